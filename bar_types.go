@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ==================== ALTERNATIVE BAR TYPES ====================
+
+// Supported bar types
+const (
+	BAR_TYPE_TIME        = "time"
+	BAR_TYPE_HEIKIN_ASHI = "heikin-ashi"
+	BAR_TYPE_RENKO       = "renko"
+	BAR_TYPE_RANGE       = "range"
+	BAR_TYPE_VOLUME      = "volume"
+	BAR_TYPE_DOLLAR      = "dollar"
+)
+
+// BarConfig selects how the time-based kline stream is converted before analysis
+type BarConfig struct {
+	Type          string  // One of the BAR_TYPE_* values (default: "time")
+	BoxSize       float64 // Renko box / range bar size in price units (0 = ATR based)
+	ATRPeriod     int     // ATR period used when BoxSize is 0 (default: 14)
+	ATRMultiplier float64 // Box = ATR * multiplier when BoxSize is 0 (default: 1.0)
+	Threshold     float64 // Volume (base) or dollar (quote) volume per bar (0 = average per candle)
+}
+
+// DefaultBarConfig returns plain time-based bars
+func DefaultBarConfig() BarConfig {
+	return BarConfig{
+		Type:          BAR_TYPE_TIME,
+		ATRPeriod:     14,
+		ATRMultiplier: 1.0,
+	}
+}
+
+// Global bar configuration (set from flags, copied into each TradingEngine)
+var BAR_CONFIG = DefaultBarConfig()
+
+// BuildBars converts time-based candles into the configured bar type. The
+// output is a []Candle so the RSI/pivot/divergence pipeline runs unchanged.
+func BuildBars(candles []Candle, config BarConfig) ([]Candle, error) {
+	switch config.Type {
+	case "", BAR_TYPE_TIME:
+		return candles, nil
+	case BAR_TYPE_HEIKIN_ASHI:
+		return buildHeikinAshi(candles), nil
+	case BAR_TYPE_RENKO:
+		return buildRenko(candles, boxSizes(candles, config)), nil
+	case BAR_TYPE_RANGE:
+		return buildRangeBars(candles, boxSizes(candles, config)), nil
+	case BAR_TYPE_VOLUME:
+		return buildVolumeBars(candles, config.Threshold, false), nil
+	case BAR_TYPE_DOLLAR:
+		return buildVolumeBars(candles, config.Threshold, true), nil
+	default:
+		return nil, fmt.Errorf("unknown bar type %q", config.Type)
+	}
+}
+
+// boxSizes returns the box size in effect at each candle: the fixed size, or
+// ATR * multiplier as known at that candle (0 until the ATR has warmed up)
+func boxSizes(candles []Candle, config BarConfig) []float64 {
+	sizes := make([]float64, len(candles))
+	if config.BoxSize > 0 {
+		for i := range sizes {
+			sizes[i] = config.BoxSize
+		}
+		return sizes
+	}
+
+	atr := calcATR(candles, config.ATRPeriod)
+	for i, value := range atr {
+		if value > 0 {
+			sizes[i] = value * config.ATRMultiplier
+		}
+	}
+	return sizes
+}
+
+// buildHeikinAshi computes Heikin-Ashi candles (same timestamps and volumes)
+func buildHeikinAshi(candles []Candle) []Candle {
+	bars := make([]Candle, len(candles))
+
+	for i, c := range candles {
+		ha := c
+		ha.Close = (c.Open + c.High + c.Low + c.Close) / 4
+		if i == 0 {
+			ha.Open = (c.Open + c.Close) / 2
+		} else {
+			ha.Open = (bars[i-1].Open + bars[i-1].Close) / 2
+		}
+		ha.High = math.Max(c.High, math.Max(ha.Open, ha.Close))
+		ha.Low = math.Min(c.Low, math.Min(ha.Open, ha.Close))
+		bars[i] = ha
+	}
+
+	return bars
+}
+
+// buildRenko builds classic close-based Renko bricks. A new brick needs one box
+// in the current direction or two boxes for a reversal. Volume traded between
+// bricks is attributed to the first brick formed. Bricks start at the first
+// candle with a box size; several bricks from one candle split its time span.
+func buildRenko(candles []Candle, boxSizes []float64) []Candle {
+	start := 0
+	for start < len(candles) && boxSizes[start] <= 0 {
+		start++
+	}
+	if start == len(candles) {
+		return nil
+	}
+
+	var bricks []Candle
+	lastClose := candles[start].Close
+	direction := 0 // 1 = up, -1 = down, 0 = none yet
+	var pending Candle
+
+	addBrick := func(src Candle, open, close float64) {
+		brick := Candle{
+			OpenTime:  src.OpenTime,
+			CloseTime: src.CloseTime,
+			Open:      open,
+			Close:     close,
+			High:      math.Max(open, close),
+			Low:       math.Min(open, close),
+		}
+		// First brick after a gap carries the accumulated volume
+		brick.Volume = pending.Volume
+		brick.QuoteAssetVolume = pending.QuoteAssetVolume
+		brick.NumberOfTrades = pending.NumberOfTrades
+		brick.TakerBuyBaseAssetVolume = pending.TakerBuyBaseAssetVolume
+		brick.TakerBuyQuoteAssetVolume = pending.TakerBuyQuoteAssetVolume
+		pending = Candle{}
+		bricks = append(bricks, brick)
+	}
+
+	for i := start; i < len(candles); i++ {
+		c, boxSize := candles[i], boxSizes[i]
+		accumulateVolume(&pending, c)
+		first := len(bricks)
+
+		for {
+			if direction >= 0 && c.Close >= lastClose+boxSize {
+				addBrick(c, lastClose, lastClose+boxSize)
+				lastClose += boxSize
+				direction = 1
+			} else if direction <= 0 && c.Close <= lastClose-boxSize {
+				addBrick(c, lastClose, lastClose-boxSize)
+				lastClose -= boxSize
+				direction = -1
+			} else if direction == 1 && c.Close <= lastClose-2*boxSize {
+				// Reversal down: brick opens at the previous brick's open
+				addBrick(c, lastClose-boxSize, lastClose-2*boxSize)
+				lastClose -= 2 * boxSize
+				direction = -1
+			} else if direction == -1 && c.Close >= lastClose+2*boxSize {
+				// Reversal up
+				addBrick(c, lastClose+boxSize, lastClose+2*boxSize)
+				lastClose += 2 * boxSize
+				direction = 1
+			} else {
+				break
+			}
+		}
+
+		spreadBrickTimes(bricks[first:], c)
+	}
+
+	return bricks
+}
+
+// spreadBrickTimes gives the bricks formed from one candle distinct,
+// increasing times across the candle's span so time-keyed logic can tell
+// them apart
+func spreadBrickTimes(bricks []Candle, src Candle) {
+	if len(bricks) < 2 {
+		return
+	}
+
+	step := src.CloseTime.Sub(src.OpenTime) / time.Duration(len(bricks))
+	if step <= 0 {
+		step = time.Millisecond
+	}
+	for k := range bricks {
+		bricks[k].OpenTime = src.OpenTime.Add(time.Duration(k) * step)
+		bricks[k].CloseTime = bricks[k].OpenTime.Add(step)
+	}
+}
+
+// buildRangeBars closes a bar once its High-Low range reaches the range size
+// known at its last candle. The still-forming last bar is included so the
+// latest price stays visible.
+func buildRangeBars(candles []Candle, rangeSizes []float64) []Candle {
+	start := 0
+	for start < len(candles) && rangeSizes[start] <= 0 {
+		start++
+	}
+	if start == len(candles) {
+		return nil
+	}
+
+	var bars []Candle
+	current := candles[start]
+
+	for i := start + 1; i < len(candles); i++ {
+		if current.High-current.Low >= rangeSizes[i-1] {
+			bars = append(bars, current)
+			current = candles[i]
+			continue
+		}
+		mergeCandle(&current, candles[i])
+	}
+	bars = append(bars, current)
+
+	return bars
+}
+
+// buildVolumeBars closes a bar once accumulated base volume (or quote volume
+// for dollar bars) reaches threshold. A zero threshold uses the average volume
+// per source candle, so the bar count roughly matches the candle count.
+func buildVolumeBars(candles []Candle, threshold float64, dollar bool) []Candle {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	volumeOf := func(c Candle) float64 {
+		if dollar {
+			return c.QuoteAssetVolume
+		}
+		return c.Volume
+	}
+
+	if threshold <= 0 {
+		total := 0.0
+		for _, c := range candles {
+			total += volumeOf(c)
+		}
+		threshold = total / float64(len(candles))
+	}
+	if threshold <= 0 {
+		return candles
+	}
+
+	var bars []Candle
+	current := candles[0]
+
+	for i := 1; i < len(candles); i++ {
+		if volumeOf(current) >= threshold {
+			bars = append(bars, current)
+			current = candles[i]
+			continue
+		}
+		mergeCandle(&current, candles[i])
+	}
+	bars = append(bars, current)
+
+	return bars
+}
+
+// mergeCandle extends bar with the next source candle
+func mergeCandle(bar *Candle, c Candle) {
+	bar.High = math.Max(bar.High, c.High)
+	bar.Low = math.Min(bar.Low, c.Low)
+	bar.Close = c.Close
+	bar.CloseTime = c.CloseTime
+	accumulateVolume(bar, c)
+}
+
+// accumulateVolume adds c's volume fields to bar
+func accumulateVolume(bar *Candle, c Candle) {
+	bar.Volume += c.Volume
+	bar.QuoteAssetVolume += c.QuoteAssetVolume
+	bar.NumberOfTrades += c.NumberOfTrades
+	bar.TakerBuyBaseAssetVolume += c.TakerBuyBaseAssetVolume
+	bar.TakerBuyQuoteAssetVolume += c.TakerBuyQuoteAssetVolume
+}
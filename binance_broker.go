@@ -0,0 +1,319 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ==================== BINANCE BROKER ====================
+
+// Binance rejects signed requests older than this (milliseconds)
+const BINANCE_RECV_WINDOW = 5000
+
+// BinanceBroker places orders through the Binance REST API with HMAC-SHA256
+// signed requests. BaseURL can point at a testnet or a local mock server.
+// Events are published from the REST responses (there's no user data stream).
+type BinanceBroker struct {
+	BaseURL   string
+	APIKey    string
+	APISecret string
+	Futures   bool
+	Client    *http.Client
+	events    chan BrokerEvent
+}
+
+// NewBinanceBroker creates a broker for the spot or USDⓈ-M futures API
+func NewBinanceBroker(baseURL, apiKey, apiSecret string, futures bool) *BinanceBroker {
+	return &BinanceBroker{
+		BaseURL:   baseURL,
+		APIKey:    apiKey,
+		APISecret: apiSecret,
+		Futures:   futures,
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		events:    make(chan BrokerEvent, BROKER_EVENT_BUFFER),
+	}
+}
+
+func (b *BinanceBroker) Name() string { return BROKER_BINANCE }
+
+func (b *BinanceBroker) Events() <-chan BrokerEvent { return b.events }
+
+// sign adds the timestamp and returns the query with its HMAC-SHA256 signature
+func (b *BinanceBroker) sign(params url.Values) string {
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	params.Set("recvWindow", strconv.Itoa(BINANCE_RECV_WINDOW))
+	query := params.Encode()
+
+	mac := hmac.New(sha256.New, []byte(b.APISecret))
+	mac.Write([]byte(query))
+	return query + "&signature=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedRequest sends a signed request and returns the response body
+func (b *BinanceBroker) signedRequest(method, path string, params url.Values) ([]byte, error) {
+	req, err := http.NewRequest(method, b.BaseURL+path+"?"+b.sign(params), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-MBX-APIKEY", b.APIKey)
+
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Msg != "" {
+			return nil, fmt.Errorf("%s %s: binance error %d: %s", method, path, apiErr.Code, apiErr.Msg)
+		}
+		return nil, fmt.Errorf("%s %s: HTTP %d", method, path, resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// orderPath returns the order endpoint for the market
+func (b *BinanceBroker) orderPath() string {
+	if b.Futures {
+		return "/fapi/v1/order"
+	}
+	return "/api/v3/order"
+}
+
+// binanceOrderResponse covers the spot and futures order responses
+type binanceOrderResponse struct {
+	OrderID             int64  `json:"orderId"`
+	ClientOrderID       string `json:"clientOrderId"`
+	Symbol              string `json:"symbol"`
+	Status              string `json:"status"`
+	Side                string `json:"side"`
+	Type                string `json:"type"`
+	OrigQty             string `json:"origQty"`
+	ExecutedQty         string `json:"executedQty"`
+	AvgPrice            string `json:"avgPrice"`            // Futures
+	CumQuote            string `json:"cumQuote"`            // Futures
+	CummulativeQuoteQty string `json:"cummulativeQuoteQty"` // Spot
+	Price               string `json:"price"`
+	StopPrice           string `json:"stopPrice"`
+	ReduceOnly          bool   `json:"reduceOnly"`
+	UpdateTime          int64  `json:"updateTime"`   // Futures
+	TransactTime        int64  `json:"transactTime"` // Spot
+}
+
+// toOrder converts a response to an Order
+func (r binanceOrderResponse) toOrder() Order {
+	order := Order{
+		ID:            strconv.FormatInt(r.OrderID, 10),
+		ClientOrderID: r.ClientOrderID,
+		Symbol:        r.Symbol,
+		Side:          r.Side,
+		Type:          r.Type,
+		Status:        r.Status,
+		Quantity:      parseFilterValue(r.OrigQty),
+		Price:         parseFilterValue(r.Price),
+		StopPrice:     parseFilterValue(r.StopPrice),
+		ExecutedQty:   parseFilterValue(r.ExecutedQty),
+		AvgPrice:      parseFilterValue(r.AvgPrice),
+		ReduceOnly:    r.ReduceOnly,
+	}
+
+	if order.AvgPrice == 0 && order.ExecutedQty > 0 {
+		quote := parseFilterValue(r.CumQuote)
+		if quote == 0 {
+			quote = parseFilterValue(r.CummulativeQuoteQty)
+		}
+		order.AvgPrice = quote / order.ExecutedQty
+	}
+
+	updated := r.UpdateTime
+	if updated == 0 {
+		updated = r.TransactTime
+	}
+	if updated > 0 {
+		order.UpdateTime = time.UnixMilli(updated)
+	} else {
+		order.UpdateTime = time.Now()
+	}
+	return order
+}
+
+// PlaceOrder sends an order and waits for its result
+func (b *BinanceBroker) PlaceOrder(req OrderRequest) (Order, error) {
+	params := url.Values{}
+	params.Set("symbol", req.Symbol)
+	params.Set("side", req.Side)
+	params.Set("quantity", strconv.FormatFloat(req.Quantity, 'f', -1, 64))
+	params.Set("newOrderRespType", "RESULT")
+	if req.ClientOrderID != "" {
+		params.Set("newClientOrderId", req.ClientOrderID)
+	}
+
+	orderType := req.Type
+	switch req.Type {
+	case ORDER_TYPE_STOP_MARKET:
+		if !b.Futures {
+			orderType = "STOP_LOSS" // Spot's market stop
+		}
+	case ORDER_TYPE_STOP_LIMIT:
+		if !b.Futures {
+			orderType = "STOP_LOSS_LIMIT"
+		}
+	}
+	if req.Type == ORDER_TYPE_LIMIT || req.Type == ORDER_TYPE_STOP_LIMIT {
+		params.Set("price", strconv.FormatFloat(req.Price, 'f', -1, 64))
+		params.Set("timeInForce", "GTC")
+	}
+	if req.Type == ORDER_TYPE_STOP_MARKET || req.Type == ORDER_TYPE_STOP_LIMIT {
+		params.Set("stopPrice", strconv.FormatFloat(req.StopPrice, 'f', -1, 64))
+	}
+	params.Set("type", orderType)
+	if req.ReduceOnly && b.Futures {
+		params.Set("reduceOnly", "true")
+	}
+
+	body, err := b.signedRequest(http.MethodPost, b.orderPath(), params)
+	if err != nil {
+		return Order{Symbol: req.Symbol, Side: req.Side, Type: req.Type, Status: ORDER_STATUS_REJECTED}, err
+	}
+
+	var resp binanceOrderResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Order{}, fmt.Errorf("failed to parse order response: %w", err)
+	}
+	order := resp.toOrder()
+	order.Type = req.Type
+
+	publishEvent(b.events, BrokerEvent{Type: BROKER_EVENT_ORDER, Order: order})
+	if order.ExecutedQty > 0 {
+		publishEvent(b.events, BrokerEvent{Type: BROKER_EVENT_FILL, Order: order, Fill: &Fill{
+			OrderID:  order.ID,
+			Symbol:   order.Symbol,
+			Side:     order.Side,
+			Quantity: order.ExecutedQty,
+			Price:    order.AvgPrice,
+			Time:     order.UpdateTime,
+		}})
+	}
+	return order, nil
+}
+
+// CancelOrder cancels an open order
+func (b *BinanceBroker) CancelOrder(symbol, orderID string) error {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", orderID)
+
+	body, err := b.signedRequest(http.MethodDelete, b.orderPath(), params)
+	if err != nil {
+		return err
+	}
+
+	var resp binanceOrderResponse
+	if err := json.Unmarshal(body, &resp); err == nil {
+		publishEvent(b.events, BrokerEvent{Type: BROKER_EVENT_ORDER, Order: resp.toOrder()})
+	}
+	return nil
+}
+
+// GetPositions returns open futures positions (spot has none)
+func (b *BinanceBroker) GetPositions() ([]BrokerPosition, error) {
+	if !b.Futures {
+		return nil, fmt.Errorf("positions are only available on futures")
+	}
+
+	body, err := b.signedRequest(http.MethodGet, "/fapi/v2/positionRisk", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	var risks []struct {
+		Symbol           string `json:"symbol"`
+		PositionAmt      string `json:"positionAmt"`
+		EntryPrice       string `json:"entryPrice"`
+		UnRealizedProfit string `json:"unRealizedProfit"`
+	}
+	if err := json.Unmarshal(body, &risks); err != nil {
+		return nil, fmt.Errorf("failed to parse positions: %w", err)
+	}
+
+	var positions []BrokerPosition
+	for _, r := range risks {
+		quantity := parseFilterValue(r.PositionAmt)
+		if quantity == 0 {
+			continue
+		}
+		positions = append(positions, BrokerPosition{
+			Symbol:        r.Symbol,
+			Quantity:      quantity,
+			EntryPrice:    parseFilterValue(r.EntryPrice),
+			UnrealizedPnL: parseFilterValue(r.UnRealizedProfit),
+		})
+	}
+	return positions, nil
+}
+
+// GetBalances returns non-zero asset balances
+func (b *BinanceBroker) GetBalances() ([]Balance, error) {
+	var balances []Balance
+
+	if b.Futures {
+		body, err := b.signedRequest(http.MethodGet, "/fapi/v2/balance", url.Values{})
+		if err != nil {
+			return nil, err
+		}
+		var assets []struct {
+			Asset            string `json:"asset"`
+			Balance          string `json:"balance"`
+			AvailableBalance string `json:"availableBalance"`
+		}
+		if err := json.Unmarshal(body, &assets); err != nil {
+			return nil, fmt.Errorf("failed to parse balances: %w", err)
+		}
+		for _, a := range assets {
+			if total := parseFilterValue(a.Balance); total != 0 {
+				balances = append(balances, Balance{Asset: a.Asset, Total: total, Available: parseFilterValue(a.AvailableBalance)})
+			}
+		}
+		return balances, nil
+	}
+
+	body, err := b.signedRequest(http.MethodGet, "/api/v3/account", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	var account struct {
+		Balances []struct {
+			Asset  string `json:"asset"`
+			Free   string `json:"free"`
+			Locked string `json:"locked"`
+		} `json:"balances"`
+	}
+	if err := json.Unmarshal(body, &account); err != nil {
+		return nil, fmt.Errorf("failed to parse balances: %w", err)
+	}
+	for _, a := range account.Balances {
+		free, locked := parseFilterValue(a.Free), parseFilterValue(a.Locked)
+		if free+locked != 0 {
+			balances = append(balances, Balance{Asset: a.Asset, Total: free + locked, Available: free})
+		}
+	}
+	return balances, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// mockBinance serves the futures order endpoint and checks each request's
+// API key header and HMAC-SHA256 signature
+func mockBinance(t *testing.T, key, secret string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/order" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("X-MBX-APIKEY"); got != key {
+			t.Errorf("API key header = %q, want %q", got, key)
+		}
+
+		query, signature, found := strings.Cut(r.URL.RawQuery, "&signature=")
+		if !found {
+			t.Errorf("unsigned request: %s", r.URL.RawQuery)
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code":-1102,"msg":"Mandatory parameter 'signature' was not sent."}`)
+			return
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(query))
+		if want := hex.EncodeToString(mac.Sum(nil)); signature != want {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code":-1022,"msg":"Signature for this request is not valid."}`)
+			return
+		}
+
+		params := r.URL.Query()
+		for _, name := range []string{"symbol", "timestamp", "recvWindow"} {
+			if params.Get(name) == "" {
+				t.Errorf("missing %s in %s", name, query)
+			}
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			if params.Get("type") != ORDER_TYPE_LIMIT || params.Get("price") != "101.5" || params.Get("timeInForce") != "GTC" {
+				t.Errorf("unexpected order params: %s", query)
+			}
+			fmt.Fprintf(w, `{"orderId":42,"symbol":%q,"status":"NEW","side":%q,"type":"LIMIT",
+				"origQty":%q,"executedQty":"0","avgPrice":"0","price":"101.5","updateTime":1700000000000}`,
+				params.Get("symbol"), params.Get("side"), params.Get("quantity"))
+		case http.MethodDelete:
+			if params.Get("orderId") != "42" {
+				t.Errorf("cancel orderId = %q", params.Get("orderId"))
+			}
+			fmt.Fprintf(w, `{"orderId":42,"symbol":%q,"status":"CANCELED","side":"SELL","type":"LIMIT",
+				"origQty":"0.5","executedQty":"0","price":"101.5","updateTime":1700000001000}`, params.Get("symbol"))
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	}))
+}
+
+func TestBinanceBrokerOrderRoundTrip(t *testing.T) {
+	server := mockBinance(t, "key", "secret")
+	defer server.Close()
+
+	broker := NewBinanceBroker(server.URL, "key", "secret", true)
+	order, err := broker.PlaceOrder(OrderRequest{
+		Symbol:   "BTCUSDT",
+		Side:     ORDER_SIDE_SELL,
+		Type:     ORDER_TYPE_LIMIT,
+		Quantity: 0.5,
+		Price:    101.5,
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if order.ID != "42" || order.Status != ORDER_STATUS_NEW || order.Quantity != 0.5 || order.Price != 101.5 {
+		t.Fatalf("unexpected order: %+v", order)
+	}
+
+	if err := broker.CancelOrder("BTCUSDT", order.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+
+	var statuses []string
+	for len(broker.Events()) > 0 {
+		statuses = append(statuses, (<-broker.Events()).Order.Status)
+	}
+	if strings.Join(statuses, ",") != ORDER_STATUS_NEW+","+ORDER_STATUS_CANCELED {
+		t.Errorf("events = %v", statuses)
+	}
+}
+
+func TestBinanceBrokerBadSignature(t *testing.T) {
+	server := mockBinance(t, "key", "secret")
+	defer server.Close()
+
+	broker := NewBinanceBroker(server.URL, "key", "wrong", true)
+	order, err := broker.PlaceOrder(OrderRequest{Symbol: "BTCUSDT", Side: ORDER_SIDE_SELL, Type: ORDER_TYPE_LIMIT, Quantity: 0.5, Price: 101.5})
+	if err == nil || !strings.Contains(err.Error(), "-1022") {
+		t.Fatalf("want signature error, got %v", err)
+	}
+	if order.Status != ORDER_STATUS_REJECTED {
+		t.Errorf("status = %s, want %s", order.Status, ORDER_STATUS_REJECTED)
+	}
+}
+
+func TestNewBrokerBinanceNeedsFutures(t *testing.T) {
+	t.Setenv("BINANCE_API_KEY", "key")
+	t.Setenv("BINANCE_API_SECRET", "secret")
+	defer func(futures bool) { USE_FUTURES = futures }(USE_FUTURES)
+
+	USE_FUTURES = false
+	if _, err := NewBroker(BROKER_BINANCE, 1000); err == nil {
+		t.Error("spot binance broker accepted")
+	}
+	USE_FUTURES = true
+	if _, err := NewBroker(BROKER_BINANCE, 1000); err != nil {
+		t.Errorf("futures binance broker: %v", err)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
+
+	"example.com/bot/internal/trademanager"
 )
 
 // Candle represents a single kline/candlestick from Binance
@@ -44,10 +47,59 @@ func GetKlinesEndpoint() string {
 	return "/api/v3/klines"
 }
 
+// Global source interval for resampling (empty = fetch each interval directly)
+var SOURCE_INTERVAL = ""
+
+// Maximum klines Binance returns per request
+const MAX_KLINES_PER_REQUEST = 1000
+
 func fetchKlines(symbol, interval string, limit int) ([]Candle, error) {
+	return fetchKlinesWithParams(symbol, interval, limit, time.Time{}, time.Time{})
+}
+
+// fetchKlinesPaged fetches more than MAX_KLINES_PER_REQUEST candles by walking
+// backwards with endTime. Returned candles are oldest first.
+func fetchKlinesPaged(symbol, interval string, total int) ([]Candle, error) {
+	var candles []Candle
+	var endTime time.Time
+
+	for len(candles) < total {
+		batchSize := total - len(candles)
+		if batchSize > MAX_KLINES_PER_REQUEST {
+			batchSize = MAX_KLINES_PER_REQUEST
+		}
+
+		batch, err := fetchKlinesWithParams(symbol, interval, batchSize, time.Time{}, endTime)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		candles = append(batch, candles...)
+		endTime = batch[0].OpenTime.Add(-time.Millisecond)
+
+		if len(batch) < batchSize {
+			break // No more history available
+		}
+	}
+
+	return candles, nil
+}
+
+// fetchKlinesWithParams fetches klines with optional startTime/endTime bounds
+// (zero times are omitted from the request)
+func fetchKlinesWithParams(symbol, interval string, limit int, startTime, endTime time.Time) ([]Candle, error) {
 	baseURL := GetBaseURL()
 	endpoint := GetKlinesEndpoint()
 	url := fmt.Sprintf("%s%s?symbol=%s&interval=%s&limit=%d", baseURL, endpoint, symbol, interval, limit)
+	if !startTime.IsZero() {
+		url += fmt.Sprintf("&startTime=%d", startTime.UnixMilli())
+	}
+	if !endTime.IsZero() {
+		url += fmt.Sprintf("&endTime=%d", endTime.UnixMilli())
+	}
 
 	resp, err := http.Get(url)
 	if err != nil {
@@ -68,12 +120,20 @@ func fetchKlines(symbol, interval string, limit int) ([]Candle, error) {
 		if len(k) < 11 { // basic sanity
 			continue
 		}
-		// Helper to parse float strings
+		// Helper to parse float strings (NaN marks unparseable values so
+		// ValidateCandles rejects them instead of treating them as zero)
 		parseF := func(v interface{}) float64 {
-			s, _ := v.(string)
-			f, _ := strconv.ParseFloat(s, 64)
-			return f
+			switch val := v.(type) {
+			case float64:
+				return val
+			case string:
+				if f, err := strconv.ParseFloat(val, 64); err == nil {
+					return f
+				}
+			}
+			return math.NaN()
 		}
+		numTrades, _ := k[8].(float64)
 		openTimeMs, _ := k[0].(float64)
 		closeTimeMs, _ := k[6].(float64)
 		c := Candle{
@@ -85,7 +145,7 @@ func fetchKlines(symbol, interval string, limit int) ([]Candle, error) {
 			Volume:                   parseF(k[5]),
 			CloseTime:                time.UnixMilli(int64(closeTimeMs)),
 			QuoteAssetVolume:         parseF(k[7]),
-			NumberOfTrades:           int64(parseF(k[8])),
+			NumberOfTrades:           int64(numTrades),
 			TakerBuyBaseAssetVolume:  parseF(k[9]),
 			TakerBuyQuoteAssetVolume: parseF(k[10]),
 		}
@@ -109,6 +169,20 @@ func main() {
 	// Multi-symbol paper trading flags
 	multiPaper := flag.Bool("multi-paper", false, "Enable multi-symbol paper trading (trade multiple coins simultaneously)")
 	maxPositions := flag.Int("max-pos", 5, "Maximum simultaneous positions (use with --multi-paper)")
+	rankBy := flag.String("rank-by", RANK_BY_SCORE, "Rank signals for free slots by: score, rr, volume (use with --multi-paper)")
+	sizing := flag.String("sizing", SIZING_FIXED, "Position sizing: fixed, risk (stop distance), atr (volatility), kelly, compound")
+	riskAction := flag.String("risk-action", RISK_ACTION_HALT, "On a daily loss/drawdown/loss streak breach: halt (stop new entries) or flatten (also close all positions)")
+	brokerMode := flag.String("broker", BROKER_PAPER, "Order execution: paper (simulated) or binance (REAL orders, needs --futures and BINANCE_API_KEY/BINANCE_API_SECRET)")
+	entryOrder := flag.String("entry-order", ENTRY_ORDER_MARKET, "Entry orders for --multi-paper: market, or limit (rest at the nearest resistance edge, paper broker only)")
+	depthFills := flag.Bool("depth-fills", false, "Paper market fills walk the live order book (partial fills, slippage) and thin books are rejected (use with --multi-paper)")
+	depthDir := flag.String("depth-dir", "", "Like --depth-fills but with recorded snapshots from DIR/<SYMBOL>.json (saved /depth responses, moved to the current price)")
+	scaleIn := flag.Bool("scale-in", false, "Add to positions when price retests the entry's resistance zone without breaking its top (use with --multi-paper)")
+	tmProfile := flag.String("tm-profile", trademanager.PROFILE_DEFAULT, "Trade manager profile for --multi-paper: default, aggressive, conservative, or a Tier 3 trail mode: chandelier (ATR), swing (pivots), psar (Parabolic SAR)")
+	maxHold := flag.Int("max-hold", 0, "Close positions after N candles (0 = off, use with --multi-paper)")
+	minProfitBy := flag.String("min-profit-by", "", "Close positions not at least PCT% in profit after N candles as PCT:N (e.g., 0.5:20; use with --multi-paper)")
+	flatWindows := flag.String("flat-window", "", "Close positions before these windows and don't enter during them, comma-separated HH:MM-HH:MM in --session-tz (e.g., 23:50-00:10; use with --multi-paper)")
+	exitLadder := flag.String("exit-ladder", "", "Partial exit ladder as trigger:exit% steps, trigger in profit % or R multiples (e.g., 0.5:25,1R:25,2R:25; use with --multi-paper)")
+	replaceWeak := flag.Bool("replace-weak", false, "Close the weakest position for a much stronger signal when slots are full (use with --multi-paper)")
 
 	// Display mode flags
 	quiet := flag.Bool("quiet", false, "Quiet mode - only show trading signals and P/L (no technical details)")
@@ -116,11 +190,137 @@ func main() {
 	// Market type flag
 	futures := flag.Bool("futures", false, "Use Binance Futures market (default: spot market)")
 
+	// Multi-timeframe flag
+	mtf := flag.String("mtf", "", "Comma-separated intervals for multi-timeframe confluence (e.g., 1m,15m,4h)")
+
+	// Bar type flags
+	barType := flag.String("bars", BAR_TYPE_TIME, "Bar type for analysis: time, heikin-ashi, renko, range, volume, dollar")
+	boxSize := flag.Float64("box-size", 0, "Renko box / range bar size in price units (0 = ATR based)")
+	barThreshold := flag.Float64("bar-threshold", 0, "Volume or dollar volume per bar (0 = average per candle)")
+
+	// Resampling flag
+	resampleFrom := flag.String("resample-from", "", "Build --interval candles from this stored interval instead of fetching it (e.g., 1m; custom intervals like 7m or 90m resample from 1m automatically)")
+
+	// Entry confirmation flag
+	requirePattern := flag.Bool("require-pattern", false, "Only open shorts after a bearish candlestick pattern at a resistance zone")
+	scoreSizing := flag.Bool("score-sizing", false, "Scale position size by the signal score (0.5x at score 0 up to 1x at 100)")
+	regimeFilter := flag.Bool("regime-filter", false, "Skip divergence shorts in regimes the strategy is disabled for (trending up, high volatility)")
+
+	// Session filter flags (only gate new entries; open positions stay managed)
+	sessionTZ := flag.String("session-tz", "UTC", "IANA time zone for --sessions, --blackouts, weekends and calendar times (e.g., America/New_York)")
+	sessions := flag.String("sessions", "", "Only open trades inside these windows, comma-separated HH:MM-HH:MM (e.g., 08:00-16:00)")
+	blackouts := flag.String("blackouts", "", "Never open trades inside these windows, comma-separated HH:MM-HH:MM")
+	blockWeekend := flag.Bool("block-weekend", false, "Don't open trades on Saturday/Sunday (in --session-tz)")
+	blockFunding := flag.Bool("block-funding", false, "Don't open trades around the 00/08/16 UTC funding settlements")
+	calendarFile := flag.String("calendar", "", "CSV of macro events to black out: time,name[,minutes before,minutes after]")
+
 	flag.Parse()
 
 	// Set market type
 	USE_FUTURES = *futures
 
+	// Set resampling source interval
+	SOURCE_INTERVAL = *resampleFrom
+
+	// Set entry confirmation
+	REQUIRE_PATTERN_CONFIRMATION = *requirePattern
+	REGIME_FILTER_ENABLED = *regimeFilter
+	SCORE_SIZING_ENABLED = *scoreSizing
+
+	// Set session filter
+	filter, err := NewSessionFilter(*sessionTZ, *sessions, *blackouts, *blockWeekend, *blockFunding, *calendarFile)
+	if err != nil {
+		fmt.Printf("❌ Session filter: %v\n", err)
+		return
+	}
+	SESSION_FILTER = filter
+	SESSION_FILTER.Print()
+
+	// Set slot allocation
+	RANK_SIGNALS_BY = strings.ToLower(*rankBy)
+	if RANK_SIGNALS_BY != RANK_BY_SCORE && RANK_SIGNALS_BY != RANK_BY_RISK_REWARD && RANK_SIGNALS_BY != RANK_BY_VOLUME {
+		fmt.Printf("❌ Invalid --rank-by %q (use score, rr or volume)\n", *rankBy)
+		return
+	}
+	REPLACE_WEAK_POSITIONS = *replaceWeak
+	SCALE_IN_ENABLED = *scaleIn
+
+	// Set position sizing
+	SIZING_MODE = strings.ToLower(*sizing)
+	if _, err := NewPositionSizer(SIZING_MODE); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	// Set depth fill simulation (before the broker picks it up)
+	if *depthFills || *depthDir != "" {
+		DEPTH_SOURCE = NewDepthSource(*depthDir)
+	}
+
+	// Set order execution broker
+	BROKER_MODE = strings.ToLower(*brokerMode)
+	if _, err := NewBroker(BROKER_MODE, *balance); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	if BROKER_MODE == BROKER_BINANCE {
+		fmt.Println("⚠️  Broker: binance - orders are sent to the exchange with REAL funds")
+	}
+
+	// Set entry order type
+	ENTRY_ORDER_TYPE = strings.ToLower(*entryOrder)
+	if ENTRY_ORDER_TYPE != ENTRY_ORDER_MARKET && ENTRY_ORDER_TYPE != ENTRY_ORDER_LIMIT {
+		fmt.Printf("❌ Invalid --entry-order %q (use market or limit)\n", *entryOrder)
+		return
+	}
+
+	// Set partial exit ladder
+	ladder, err := trademanager.ParseExitLadder(*exitLadder)
+	if err != nil {
+		fmt.Printf("❌ Invalid --exit-ladder: %v\n", err)
+		return
+	}
+	EXIT_LADDER = ladder
+
+	// Set time stops
+	if *maxHold < 0 {
+		fmt.Printf("❌ Invalid --max-hold %d (must be >= 0)\n", *maxHold)
+		return
+	}
+	MAX_HOLD_CANDLES = *maxHold
+	MIN_PROFIT_PERCENT, MIN_PROFIT_CANDLES, err = trademanager.ParseProfitCheck(*minProfitBy)
+	if err != nil {
+		fmt.Printf("❌ Invalid --min-profit-by: %v\n", err)
+		return
+	}
+	FLAT_WINDOWS, err = ParseFlatWindows(*flatWindows, *sessionTZ)
+	if err != nil {
+		fmt.Printf("❌ Invalid --flat-window: %v\n", err)
+		return
+	}
+
+	// Set trade manager profile
+	TRADE_MANAGER_PROFILE = strings.ToLower(*tmProfile)
+	if _, err := trademanager.ConfigForProfile(TRADE_MANAGER_PROFILE); err != nil {
+		fmt.Printf("❌ Invalid --tm-profile: %v\n", err)
+		return
+	}
+
+	// Set risk breach action
+	RISK_BREACH_ACTION = strings.ToLower(*riskAction)
+	if RISK_BREACH_ACTION != RISK_ACTION_HALT && RISK_BREACH_ACTION != RISK_ACTION_FLATTEN {
+		fmt.Printf("❌ Invalid --risk-action %q (use halt or flatten)\n", *riskAction)
+		return
+	}
+
+	// Set bar type
+	BAR_CONFIG.Type = strings.ToLower(*barType)
+	BAR_CONFIG.BoxSize = *boxSize
+	BAR_CONFIG.Threshold = *barThreshold
+	if BAR_CONFIG.Type != BAR_TYPE_TIME {
+		fmt.Printf("📊 Bar Type: %s\n", BAR_CONFIG.Type)
+	}
+
 	// Display market type
 	marketType := "SPOT"
 	if USE_FUTURES {
@@ -200,9 +400,16 @@ func main() {
 	// Single symbol modes
 	if *paperMode {
 		engine := NewPaperTradingEngine(*symbol, *interval, *limit, *balance)
+		if *mtf != "" {
+			engine.EnableMultiTimeframe(parseIntervalList(*mtf))
+		}
 		if err := engine.RunPaperTrading(); err != nil {
 			fmt.Printf("❌ Error: %v\n", err)
 		}
+	} else if *mtf != "" {
+		if err := RunMultiTimeframeAnalysis(*symbol, parseIntervalList(*mtf), *limit); err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+		}
 	} else {
 		RunEngine(*symbol, *interval, *limit)
 	}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==================== BROKER ====================
+
+// Broker modes (--broker)
+const (
+	BROKER_PAPER   = "paper"   // Simulated fills at the last price
+	BROKER_BINANCE = "binance" // Binance REST order API (real orders!)
+)
+
+// Order sides and types (Binance names)
+const (
+	ORDER_SIDE_BUY  = "BUY"
+	ORDER_SIDE_SELL = "SELL"
+
+	ORDER_TYPE_MARKET      = "MARKET"
+	ORDER_TYPE_LIMIT       = "LIMIT"
+	ORDER_TYPE_STOP_MARKET = "STOP_MARKET"
+	ORDER_TYPE_STOP_LIMIT  = "STOP" // Futures name; spot uses STOP_LOSS_LIMIT
+)
+
+// Order states
+const (
+	ORDER_STATUS_NEW      = "NEW"
+	ORDER_STATUS_FILLED   = "FILLED"
+	ORDER_STATUS_CANCELED = "CANCELED"
+	ORDER_STATUS_REJECTED = "REJECTED"
+	ORDER_STATUS_EXPIRED  = "EXPIRED" // Market order partly filled, the rest found no liquidity
+)
+
+// Broker event types
+const (
+	BROKER_EVENT_ORDER = "ORDER" // Order accepted, canceled or rejected
+	BROKER_EVENT_FILL  = "FILL"  // Order (partially) executed
+)
+
+// Events buffered before new ones are dropped
+const BROKER_EVENT_BUFFER = 256
+
+// Asset the account balance and P/L are held in
+const BROKER_QUOTE_ASSET = "USDT"
+
+// Active broker mode (set from flags)
+var BROKER_MODE = BROKER_PAPER
+
+// OrderRequest describes an order to place
+type OrderRequest struct {
+	Symbol        string
+	Side          string  // ORDER_SIDE_BUY or ORDER_SIDE_SELL
+	Type          string  // ORDER_TYPE_*
+	Quantity      float64 // Base asset quantity
+	Price         float64 // Limit price (limit/stop-limit), or reference price for paper market fills
+	StopPrice     float64 // Trigger price for stop orders
+	ReduceOnly    bool    // May only shrink an existing position
+	OCOGroup      string  // Orders sharing a group cancel each other when one fills (paper broker)
+	ClientOrderID string
+}
+
+// Order is the broker's view of a placed order
+type Order struct {
+	ID            string
+	ClientOrderID string
+	Symbol        string
+	Side          string
+	Type          string
+	Status        string
+	Quantity      float64
+	Price         float64
+	StopPrice     float64
+	ExecutedQty   float64
+	AvgPrice      float64
+	ReduceOnly    bool
+	OCOGroup      string
+	Triggered     bool // Stop-limit has triggered and now rests as a limit
+	CreateTime    time.Time
+	UpdateTime    time.Time
+}
+
+// Fill is an execution against an order
+type Fill struct {
+	OrderID  string
+	Symbol   string
+	Side     string
+	Quantity float64
+	Price    float64
+	Time     time.Time
+}
+
+// BrokerPosition is a net position (negative quantity = short)
+type BrokerPosition struct {
+	Symbol        string
+	Quantity      float64
+	EntryPrice    float64
+	UnrealizedPnL float64
+}
+
+// Balance is one asset's balance
+type Balance struct {
+	Asset     string
+	Total     float64
+	Available float64
+}
+
+// BrokerEvent is an order update or fill on the event stream
+type BrokerEvent struct {
+	Type  string // BROKER_EVENT_*
+	Order Order
+	Fill  *Fill // Set for BROKER_EVENT_FILL
+}
+
+// Broker places orders and reports positions and balances. Implementations
+// must be safe for concurrent use.
+type Broker interface {
+	Name() string
+	PlaceOrder(req OrderRequest) (Order, error)
+	CancelOrder(symbol, orderID string) error
+	GetPositions() ([]BrokerPosition, error)
+	GetBalances() ([]Balance, error)
+	Events() <-chan BrokerEvent
+}
+
+// entryOrderSide returns the order side that opens a position
+func entryOrderSide(positionSide string) string {
+	if positionSide == "SHORT" {
+		return ORDER_SIDE_SELL
+	}
+	return ORDER_SIDE_BUY
+}
+
+// exitOrderSide returns the order side that closes a position
+func exitOrderSide(positionSide string) string {
+	if positionSide == "SHORT" {
+		return ORDER_SIDE_BUY
+	}
+	return ORDER_SIDE_SELL
+}
+
+// publishEvent sends without blocking; events are dropped when nobody drains the stream
+func publishEvent(events chan BrokerEvent, event BrokerEvent) {
+	select {
+	case events <- event:
+	default:
+	}
+}
+
+// ==================== PAPER BROKER ====================
+
+// PaperBroker fills market orders immediately at the request's reference
+// price, or by walking the order book when Depth is set (see depth.go). Limit, stop and stop-limit orders rest in the book until
+// ProcessCandle matches them (see order_matching.go). Positions are netted
+// per symbol and realized P/L is booked to the quote balance.
+type PaperBroker struct {
+	QuoteAsset string
+	Depth      *DepthSource // Walk the book for market fills (nil = reference price)
+	balance    float64
+	positions  map[string]*BrokerPosition
+	book       []*Order // Resting orders, oldest first
+	counter    int
+	events     chan BrokerEvent
+	mutex      sync.Mutex
+}
+
+// NewPaperBroker creates a paper broker with a starting quote balance
+func NewPaperBroker(startingBalance float64) *PaperBroker {
+	return &PaperBroker{
+		QuoteAsset: BROKER_QUOTE_ASSET,
+		balance:    startingBalance,
+		positions:  make(map[string]*BrokerPosition),
+		events:     make(chan BrokerEvent, BROKER_EVENT_BUFFER),
+	}
+}
+
+func (b *PaperBroker) Name() string { return BROKER_PAPER }
+
+func (b *PaperBroker) Events() <-chan BrokerEvent { return b.events }
+
+// PlaceOrder fills market orders at req.Price (or the depth price) and rests
+// the other types
+func (b *PaperBroker) PlaceOrder(req OrderRequest) (Order, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.counter++
+	now := time.Now()
+	order := Order{
+		ID:            strconv.Itoa(b.counter),
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Status:        ORDER_STATUS_NEW,
+		Quantity:      req.Quantity,
+		Price:         req.Price,
+		StopPrice:     req.StopPrice,
+		ReduceOnly:    req.ReduceOnly,
+		OCOGroup:      req.OCOGroup,
+		CreateTime:    now,
+		UpdateTime:    now,
+	}
+
+	reject := func(err error) (Order, error) {
+		order.Status = ORDER_STATUS_REJECTED
+		publishEvent(b.events, BrokerEvent{Type: BROKER_EVENT_ORDER, Order: order})
+		return order, err
+	}
+
+	if req.Quantity <= 0 {
+		return reject(fmt.Errorf("invalid quantity %.8f", req.Quantity))
+	}
+	if req.Side != ORDER_SIDE_BUY && req.Side != ORDER_SIDE_SELL {
+		return reject(fmt.Errorf("invalid side %q", req.Side))
+	}
+
+	switch req.Type {
+	case ORDER_TYPE_MARKET:
+		if req.Price <= 0 {
+			return reject(fmt.Errorf("paper market order for %s needs a reference price", req.Symbol))
+		}
+		quantity := req.Quantity
+		if req.ReduceOnly {
+			quantity = b.reducibleQuantity(req.Symbol, req.Side, quantity)
+			if quantity <= 0 {
+				return reject(fmt.Errorf("reduce-only %s order would not reduce the %s position", req.Side, req.Symbol))
+			}
+		}
+		price, partial := req.Price, false
+		if b.Depth != nil {
+			var err error
+			if quantity, price, partial, err = b.depthMarketFill(req, quantity); err != nil {
+				return reject(err)
+			}
+		}
+		b.fill(&order, quantity, price)
+		if partial {
+			order.Status = ORDER_STATUS_EXPIRED
+		}
+		return order, nil
+
+	case ORDER_TYPE_LIMIT, ORDER_TYPE_STOP_MARKET, ORDER_TYPE_STOP_LIMIT:
+		if req.Type != ORDER_TYPE_STOP_MARKET && req.Price <= 0 {
+			return reject(fmt.Errorf("%s order for %s needs a limit price", req.Type, req.Symbol))
+		}
+		if req.Type != ORDER_TYPE_LIMIT && req.StopPrice <= 0 {
+			return reject(fmt.Errorf("%s order for %s needs a stop price", req.Type, req.Symbol))
+		}
+		resting := order
+		b.book = append(b.book, &resting)
+		publishEvent(b.events, BrokerEvent{Type: BROKER_EVENT_ORDER, Order: order})
+		return order, nil
+	}
+
+	return reject(fmt.Errorf("order type %s not supported by the paper broker", req.Type))
+}
+
+// signedQuantity returns +quantity for buys and -quantity for sells
+func signedQuantity(side string, quantity float64) float64 {
+	if side == ORDER_SIDE_SELL {
+		return -quantity
+	}
+	return quantity
+}
+
+// reducibleQuantity caps a reduce-only order at the opposing position (caller holds the lock)
+func (b *PaperBroker) reducibleQuantity(symbol, side string, quantity float64) float64 {
+	pos, exists := b.positions[symbol]
+	if !exists || pos.Quantity == 0 || math.Signbit(pos.Quantity) == math.Signbit(signedQuantity(side, 1)) {
+		return 0
+	}
+	return math.Min(quantity, math.Abs(pos.Quantity))
+}
+
+// fill executes an order, updating the net position and booking realized P/L
+// (caller holds the lock)
+func (b *PaperBroker) fill(order *Order, quantity, price float64) {
+	pos, exists := b.positions[order.Symbol]
+	if !exists {
+		pos = &BrokerPosition{Symbol: order.Symbol}
+		b.positions[order.Symbol] = pos
+	}
+
+	delta := signedQuantity(order.Side, quantity)
+	if pos.Quantity != 0 && math.Signbit(pos.Quantity) != math.Signbit(delta) {
+		// Closing (part of) the position realizes P/L on the closed quantity
+		closed := math.Min(math.Abs(delta), math.Abs(pos.Quantity))
+		pnl := (price - pos.EntryPrice) * closed
+		if pos.Quantity < 0 {
+			pnl = -pnl
+		}
+		b.balance += pnl
+
+		if math.Abs(delta) > math.Abs(pos.Quantity) {
+			pos.EntryPrice = price // Flipped - the remainder opens at this price
+		}
+	} else {
+		// Opening or adding averages the entry
+		total := math.Abs(pos.Quantity) + math.Abs(delta)
+		pos.EntryPrice = (pos.EntryPrice*math.Abs(pos.Quantity) + price*math.Abs(delta)) / total
+	}
+	pos.Quantity += delta
+	if math.Abs(pos.Quantity) < 1e-12 {
+		delete(b.positions, order.Symbol)
+	}
+
+	order.Status = ORDER_STATUS_FILLED
+	order.ExecutedQty = quantity
+	order.AvgPrice = price
+	order.UpdateTime = time.Now()
+
+	fill := &Fill{OrderID: order.ID, Symbol: order.Symbol, Side: order.Side,
+		Quantity: quantity, Price: price, Time: order.UpdateTime}
+	publishEvent(b.events, BrokerEvent{Type: BROKER_EVENT_FILL, Order: *order, Fill: fill})
+}
+
+// CancelOrder cancels a resting order
+func (b *PaperBroker) CancelOrder(symbol, orderID string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	order := b.removeOrder(orderID)
+	if order == nil || order.Symbol != symbol {
+		return fmt.Errorf("no open order %s for %s", orderID, symbol)
+	}
+
+	b.cancelled(order)
+	return nil
+}
+
+// removeOrder takes an order out of the book, returning nil if it isn't resting
+// (caller holds the lock)
+func (b *PaperBroker) removeOrder(orderID string) *Order {
+	for i, order := range b.book {
+		if order.ID == orderID {
+			b.book = append(b.book[:i], b.book[i+1:]...)
+			return order
+		}
+	}
+	return nil
+}
+
+// cancelled marks a removed order canceled and publishes it (caller holds the lock)
+func (b *PaperBroker) cancelled(order *Order) {
+	order.Status = ORDER_STATUS_CANCELED
+	order.UpdateTime = time.Now()
+	publishEvent(b.events, BrokerEvent{Type: BROKER_EVENT_ORDER, Order: *order})
+}
+
+// OpenOrders returns the resting orders for a symbol ("" = all)
+func (b *PaperBroker) OpenOrders(symbol string) []Order {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var orders []Order
+	for _, order := range b.book {
+		if symbol == "" || order.Symbol == symbol {
+			orders = append(orders, *order)
+		}
+	}
+	return orders
+}
+
+// GetPositions returns the open net positions
+func (b *PaperBroker) GetPositions() ([]BrokerPosition, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	positions := make([]BrokerPosition, 0, len(b.positions))
+	for _, pos := range b.positions {
+		positions = append(positions, *pos)
+	}
+	return positions, nil
+}
+
+// GetBalances returns the quote balance (starting balance plus realized P/L)
+func (b *PaperBroker) GetBalances() ([]Balance, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return []Balance{{Asset: b.QuoteAsset, Total: b.balance, Available: b.balance}}, nil
+}
+
+// ==================== BROKER SETUP ====================
+
+// NewBroker returns the broker for a --broker mode. The Binance broker reads
+// its credentials from BINANCE_API_KEY and BINANCE_API_SECRET.
+func NewBroker(mode string, startingBalance float64) (Broker, error) {
+	switch strings.ToLower(mode) {
+	case BROKER_PAPER, "":
+		broker := NewPaperBroker(startingBalance)
+		broker.Depth = DEPTH_SOURCE
+		return broker, nil
+	case BROKER_BINANCE:
+		// Spot can't short, and brokerHasPosition can't read spot positions
+		if !USE_FUTURES {
+			return nil, fmt.Errorf("binance broker needs --futures")
+		}
+		key, secret := os.Getenv("BINANCE_API_KEY"), os.Getenv("BINANCE_API_SECRET")
+		if key == "" || secret == "" {
+			return nil, fmt.Errorf("binance broker needs BINANCE_API_KEY and BINANCE_API_SECRET")
+		}
+		return NewBinanceBroker(GetBaseURL(), key, secret, USE_FUTURES), nil
+	}
+	return nil, fmt.Errorf("unknown broker %q (use paper or binance)", mode)
+}
+
+// newConfiguredBroker returns the broker for BROKER_MODE, falling back to paper
+func newConfiguredBroker(startingBalance float64) Broker {
+	broker, err := NewBroker(BROKER_MODE, startingBalance)
+	if err != nil {
+		fmt.Printf("⚠️  %v - using %s broker\n", err, BROKER_PAPER)
+		paper := NewPaperBroker(startingBalance)
+		paper.Depth = DEPTH_SOURCE
+		return paper
+	}
+	return broker
+}
+
+// quoteBalance returns the broker's BROKER_QUOTE_ASSET balance: realized P/L
+// included, unrealized P/L of open positions not
+func quoteBalance(broker Broker) (float64, error) {
+	balances, err := broker.GetBalances()
+	if err != nil {
+		return 0, err
+	}
+	for _, balance := range balances {
+		if balance.Asset == BROKER_QUOTE_ASSET {
+			return balance.Total, nil
+		}
+	}
+	return 0, fmt.Errorf("%s broker reports no %s balance", broker.Name(), BROKER_QUOTE_ASSET)
+}
+
+// drainBrokerEvents prints queued order updates and fills (verbose mode)
+func drainBrokerEvents(broker Broker) {
+	if broker == nil {
+		return
+	}
+	for {
+		select {
+		case event := <-broker.Events():
+			if !VERBOSE_MODE {
+				continue
+			}
+			if event.Fill != nil {
+				fmt.Printf("   🧾 [%s] %s fill %.8g @ $%.4f (order %s)\n",
+					event.Fill.Symbol, event.Fill.Side, event.Fill.Quantity, event.Fill.Price, event.Fill.OrderID)
+			} else {
+				fmt.Printf("   🧾 [%s] %s %s order %s %s\n",
+					event.Order.Symbol, event.Order.Side, event.Order.Type, event.Order.ID, event.Order.Status)
+			}
+		default:
+			return
+		}
+	}
+}
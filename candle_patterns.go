@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ==================== CANDLESTICK PATTERNS ====================
+
+// Pattern directions
+const (
+	PATTERN_BULLISH = "bullish"
+	PATTERN_BEARISH = "bearish"
+)
+
+// Require a bearish pattern at resistance before opening a short (set from flags)
+var REQUIRE_PATTERN_CONFIRMATION = false
+
+// CandlePattern is a price-action pattern ending at candle Index
+type CandlePattern struct {
+	Name      string  // "engulfing", "shooting_star", "hammer", "doji", "evening_star", "morning_star", "inside_bar", "three_bar_reversal"
+	Direction string  // PATTERN_BULLISH or PATTERN_BEARISH
+	Index     int     // Index of the last candle of the pattern
+	Bars      int     // Number of candles forming the pattern
+	High      float64 // Highest high across the pattern's candles
+	Low       float64 // Lowest low across the pattern's candles
+	Strength  float64 // 0-1, how textbook the pattern is
+	Time      string
+}
+
+// IsBearish reports whether the pattern points down
+func (p CandlePattern) IsBearish() bool {
+	return p.Direction == PATTERN_BEARISH
+}
+
+// candleBody returns the absolute body size
+func candleBody(c Candle) float64 {
+	return math.Abs(c.Close - c.Open)
+}
+
+// candleRange returns High - Low
+func candleRange(c Candle) float64 {
+	return c.High - c.Low
+}
+
+// upperWick returns the wick above the body
+func upperWick(c Candle) float64 {
+	return c.High - math.Max(c.Open, c.Close)
+}
+
+// lowerWick returns the wick below the body
+func lowerWick(c Candle) float64 {
+	return math.Min(c.Open, c.Close) - c.Low
+}
+
+// clampStrength limits a strength value to 0-1
+func clampStrength(s float64) float64 {
+	return math.Max(0, math.Min(1, s))
+}
+
+// oppositeDirection returns the direction opposite to the candle's own direction, used
+// for indecision patterns (doji, inside bar) that reverse the prior move
+func oppositeDirection(c Candle) string {
+	if c.Close >= c.Open {
+		return PATTERN_BEARISH
+	}
+	return PATTERN_BULLISH
+}
+
+// DetectCandlePatterns scans the last `lookback` candles for reversal patterns
+func DetectCandlePatterns(candles []Candle, lookback int) []CandlePattern {
+	var patterns []CandlePattern
+
+	start := len(candles) - lookback
+	if start < 0 {
+		start = 0
+	}
+
+	for i := start; i < len(candles); i++ {
+		patterns = append(patterns, detectPatternsAt(candles, i)...)
+	}
+
+	return patterns
+}
+
+// detectPatternsAt returns every pattern whose last candle is candles[i]
+func detectPatternsAt(candles []Candle, i int) []CandlePattern {
+	var found []CandlePattern
+	c := candles[i]
+	rng := candleRange(c)
+	if rng <= 0 {
+		return nil
+	}
+	body := candleBody(c)
+
+	add := func(name, direction string, bars int, strength float64) {
+		p := CandlePattern{
+			Name:      name,
+			Direction: direction,
+			Index:     i,
+			Bars:      bars,
+			High:      c.High,
+			Low:       c.Low,
+			Strength:  clampStrength(strength),
+			Time:      c.OpenTime.Format("2006-01-02 15:04"),
+		}
+		for j := i - bars + 1; j < i; j++ {
+			p.High = math.Max(p.High, candles[j].High)
+			p.Low = math.Min(p.Low, candles[j].Low)
+		}
+		found = append(found, p)
+	}
+
+	// Single-candle patterns
+	if upperWick(c) >= 2*body && lowerWick(c) <= 0.25*rng {
+		add("shooting_star", PATTERN_BEARISH, 1, upperWick(c)/rng)
+	} else if lowerWick(c) >= 2*body && upperWick(c) <= 0.25*rng {
+		add("hammer", PATTERN_BULLISH, 1, lowerWick(c)/rng)
+	} else if body <= 0.1*rng && i > 0 {
+		// Doji: indecision after a move - strength grows as the body shrinks
+		add("doji", oppositeDirection(candles[i-1]), 1, 1-body/(0.1*rng)*0.5)
+	}
+
+	if i < 1 {
+		return found
+	}
+	prev := candles[i-1]
+	prevBody := candleBody(prev)
+	prevBullish := prev.Close > prev.Open
+	bullish := c.Close > c.Open
+
+	// Engulfing: body fully covers the previous opposite-colored body
+	if prevBody > 0 && bullish != prevBullish && body > prevBody &&
+		math.Max(c.Open, c.Close) >= math.Max(prev.Open, prev.Close) &&
+		math.Min(c.Open, c.Close) <= math.Min(prev.Open, prev.Close) {
+		direction := PATTERN_BULLISH
+		if !bullish {
+			direction = PATTERN_BEARISH
+		}
+		add("engulfing", direction, 2, body/prevBody/2) // 2x the prior body = full strength
+	}
+
+	// Inside bar: range contained within the mother candle
+	prevRange := candleRange(prev)
+	if prevRange > 0 && c.High < prev.High && c.Low > prev.Low {
+		add("inside_bar", oppositeDirection(prev), 2, 1-rng/prevRange)
+	}
+
+	if i < 2 {
+		return found
+	}
+	first := candles[i-2]
+	firstBody := candleBody(first)
+	firstMid := (first.Open + first.Close) / 2
+
+	// Evening/morning star: strong candle, small-bodied pause, strong reversal
+	// closing past the first candle's midpoint
+	if firstBody > 0 && prevBody < 0.5*firstBody && body >= 0.5*firstBody {
+		if first.Close > first.Open && !bullish && c.Close < firstMid &&
+			math.Min(prev.Open, prev.Close) >= firstMid {
+			add("evening_star", PATTERN_BEARISH, 3, (first.Close-c.Close)/firstBody)
+		}
+		if first.Close < first.Open && bullish && c.Close > firstMid &&
+			math.Max(prev.Open, prev.Close) <= firstMid {
+			add("morning_star", PATTERN_BULLISH, 3, (c.Close-first.Close)/firstBody)
+		}
+	}
+
+	// Three-bar reversal: middle bar makes the extreme, third bar closes beyond
+	// the middle bar's opposite end
+	if prevRange > 0 {
+		if prev.High > first.High && prev.High > c.High && c.Close < prev.Low {
+			add("three_bar_reversal", PATTERN_BEARISH, 3, 0.5+(prev.Low-c.Close)/prevRange)
+		}
+		if prev.Low < first.Low && prev.Low < c.Low && c.Close > prev.High {
+			add("three_bar_reversal", PATTERN_BULLISH, 3, 0.5+(c.Close-prev.High)/prevRange)
+		}
+	}
+
+	return found
+}
+
+// DetectPatterns finds candlestick patterns on the latest candles
+func (e *TradingEngine) DetectPatterns() {
+	e.Patterns = DetectCandlePatterns(e.Candles, PATTERN_LOOKBACK)
+}
+
+// BearishPatternAtResistance returns the strongest bearish pattern (at or above
+// PATTERN_MIN_STRENGTH) whose high reached a resistance zone and that closed
+// below the zone's level, along with that zone. Returns nil, nil if there is none.
+func (e *TradingEngine) BearishPatternAtResistance() (*CandlePattern, *SRZone) {
+	var bestPattern *CandlePattern
+	var bestZone *SRZone
+
+	for i := range e.Patterns {
+		p := &e.Patterns[i]
+		if !p.IsBearish() || p.Strength < PATTERN_MIN_STRENGTH {
+			continue
+		}
+		if bestPattern != nil && p.Strength <= bestPattern.Strength {
+			continue
+		}
+
+		closePrice := e.Candles[p.Index].Close
+		proximity := p.High * PATTERN_ZONE_PROXIMITY_PERCENT / 100
+		for j := range e.SRZones {
+			zone := &e.SRZones[j]
+			// Only resistance counts: skip support and zones acting as support
+			if zone.IsBullish || zone.Type == "support" {
+				continue
+			}
+			// Rejected at the zone: wicked into it but closed below its level
+			if p.High >= zone.ZoneBot-proximity && p.Low <= zone.ZoneTop+proximity && closePrice < zone.Level {
+				bestPattern = p
+				bestZone = zone
+				break
+			}
+		}
+	}
+
+	return bestPattern, bestZone
+}
+
+// confirmShortEntry applies the price-action gate before opening a short.
+// Always passes when REQUIRE_PATTERN_CONFIRMATION is disabled.
+func (e *TradingEngine) confirmShortEntry() bool {
+	if !REQUIRE_PATTERN_CONFIRMATION {
+		return true
+	}
+
+	e.DetectPatterns()
+	pattern, zone := e.BearishPatternAtResistance()
+	if pattern == nil {
+		if VERBOSE_MODE {
+			fmt.Printf("   ⏸️  [%s] No bearish pattern at resistance - waiting for confirmation\n", e.Symbol)
+		}
+		return false
+	}
+
+	if VERBOSE_MODE {
+		fmt.Printf("   🕯️  [%s] %s %s (strength %.2f) at zone $%.4f-$%.4f\n",
+			e.Symbol, pattern.Direction, pattern.Name, pattern.Strength, zone.ZoneBot, zone.ZoneTop)
+	}
+	return true
+}
+
+// printPatterns lists detected patterns for the signal report
+func (e *TradingEngine) printPatterns() {
+	if len(e.Patterns) == 0 {
+		fmt.Println("🕯️  Candle Patterns: none")
+		return
+	}
+
+	fmt.Println("🕯️  Candle Patterns:")
+	for _, p := range e.Patterns {
+		fmt.Printf("   • %s %s (strength %.2f) at %s\n", p.Direction, p.Name, p.Strength, p.Time)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ==================== RE-ENTRY COOLDOWNS ====================
+
+// SymbolCooldown blocks new entries on a symbol until Until
+type SymbolCooldown struct {
+	Symbol    string
+	ClosedAt  time.Time
+	Until     time.Time
+	Loss      bool   // Longer cooldown after a losing close
+	Reason    string // Close reason (STOP_LOSS, TAKE_PROFIT, ...)
+	ProfitPct float64
+}
+
+// Remaining returns the time left on the cooldown (0 if expired)
+func (c SymbolCooldown) Remaining(now time.Time) time.Duration {
+	if now.After(c.Until) {
+		return 0
+	}
+	return c.Until.Sub(now)
+}
+
+// CooldownTracker tracks per-symbol cooldowns and the divergences that have
+// already produced a trade. Safe for concurrent use (status runs on its own goroutine).
+type CooldownTracker struct {
+	Interval        string
+	cooldowns       map[string]*SymbolCooldown
+	usedDivergences map[string]map[string]bool // symbol -> divergence ID -> used
+	mutex           sync.Mutex
+}
+
+// NewCooldownTracker creates a tracker measuring candle cooldowns on interval
+func NewCooldownTracker(interval string) *CooldownTracker {
+	return &CooldownTracker{
+		Interval:        interval,
+		cooldowns:       make(map[string]*SymbolCooldown),
+		usedDivergences: make(map[string]map[string]bool),
+	}
+}
+
+// cooldownDuration returns the longer of the candle-based and time-based
+// cooldown for the close outcome
+func cooldownDuration(interval string, loss bool) time.Duration {
+	candles, minutes := COOLDOWN_CANDLES_AFTER_WIN, COOLDOWN_MINUTES_AFTER_WIN
+	if loss {
+		candles, minutes = COOLDOWN_CANDLES_AFTER_LOSS, COOLDOWN_MINUTES_AFTER_LOSS
+	}
+
+	duration := time.Duration(minutes) * time.Minute
+	if candleDuration, err := parseIntervalDuration(interval); err == nil {
+		if byCandles := candleDuration * time.Duration(candles); byCandles > duration {
+			duration = byCandles
+		}
+	}
+	return duration
+}
+
+// RecordClose starts the cooldown for a symbol after a position closes
+func (t *CooldownTracker) RecordClose(trade *PaperTrade, reason string) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	loss := trade.NetProfitLoss() <= 0
+	closedAt := trade.ExitTime
+	if closedAt.IsZero() {
+		closedAt = time.Now()
+	}
+
+	t.cooldowns[trade.Symbol] = &SymbolCooldown{
+		Symbol:    trade.Symbol,
+		ClosedAt:  closedAt,
+		Until:     closedAt.Add(cooldownDuration(t.Interval, loss)),
+		Loss:      loss,
+		Reason:    reason,
+		ProfitPct: trade.ProfitLossPct,
+	}
+}
+
+// Active returns the symbol's cooldown if it hasn't expired yet
+func (t *CooldownTracker) Active(symbol string, now time.Time) *SymbolCooldown {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	cooldown, exists := t.cooldowns[symbol]
+	if !exists {
+		return nil
+	}
+	if cooldown.Remaining(now) == 0 {
+		delete(t.cooldowns, symbol)
+		return nil
+	}
+	snapshot := *cooldown
+	return &snapshot
+}
+
+// MarkDivergenceUsed records that a divergence produced a trade on symbol
+func (t *CooldownTracker) MarkDivergenceUsed(symbol, divergenceID string) {
+	if t == nil || divergenceID == "" {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.usedDivergences[symbol] == nil {
+		t.usedDivergences[symbol] = make(map[string]bool)
+	}
+	t.usedDivergences[symbol][divergenceID] = true
+}
+
+// DivergenceUsed reports whether a divergence already produced a trade on symbol
+func (t *CooldownTracker) DivergenceUsed(symbol, divergenceID string) bool {
+	if t == nil {
+		return false
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.usedDivergences[symbol][divergenceID]
+}
+
+// latestRecentDivergence returns the most recent divergence completed within
+// windowHours, or nil if there is none
+func latestRecentDivergence(divergences []BearishDivergence, windowHours float64) *BearishDivergence {
+	var latest *BearishDivergence
+	for i := range divergences {
+		divTime, err := time.Parse("2006-01-02 15:04", divergences[i].EndTime)
+		if err != nil || time.Since(divTime).Hours() >= windowHours {
+			continue
+		}
+		if latest == nil || divergences[i].EndIdx > latest.EndIdx {
+			latest = &divergences[i]
+		}
+	}
+	return latest
+}
+
+// Print lists active cooldowns for the status display
+func (t *CooldownTracker) Print() {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	var active []*SymbolCooldown
+	for _, cooldown := range t.cooldowns {
+		if cooldown.Remaining(now) > 0 {
+			active = append(active, cooldown)
+		}
+	}
+
+	usedCount := 0
+	for _, ids := range t.usedDivergences {
+		usedCount += len(ids)
+	}
+
+	if len(active) == 0 {
+		fmt.Printf("\n🧊 Cooldowns: none (%d divergence(s) already traded)\n", usedCount)
+		return
+	}
+
+	// Sort by time remaining, soonest first
+	for i := 0; i < len(active)-1; i++ {
+		for j := i + 1; j < len(active); j++ {
+			if active[j].Until.Before(active[i].Until) {
+				active[i], active[j] = active[j], active[i]
+			}
+		}
+	}
+
+	fmt.Printf("\n🧊 Cooldowns (%d active, %d divergence(s) already traded):\n", len(active), usedCount)
+	for _, c := range active {
+		outcome := "win"
+		if c.Loss {
+			outcome = "loss"
+		}
+		fmt.Printf("  %s: %s after %s (%+.2f%%), %s left (until %s)\n",
+			c.Symbol, outcome, c.Reason, c.ProfitPct,
+			c.Remaining(now).Round(time.Second), c.Until.Format("15:04:05"))
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ==================== CORRELATION & EXPOSURE LIMITS ====================
+
+// CorrelationMatrix computes rolling close-to-close return correlations
+// between symbols on demand from candles already fetched by the scan
+type CorrelationMatrix struct {
+	returns map[string]map[int64]float64 // symbol -> candle open time (unix) -> log return
+	cache   map[string]float64
+}
+
+// NewCorrelationMatrix builds return series from each symbol's candles
+func NewCorrelationMatrix(series map[string][]Candle) *CorrelationMatrix {
+	m := &CorrelationMatrix{
+		returns: make(map[string]map[int64]float64),
+		cache:   make(map[string]float64),
+	}
+	for symbol, candles := range series {
+		m.returns[symbol] = logReturns(candles)
+	}
+	return m
+}
+
+// CorrelationFromResults builds a matrix from the candles kept by a scan
+func CorrelationFromResults(results []MultiSymbolResult) *CorrelationMatrix {
+	series := make(map[string][]Candle)
+	for _, result := range results {
+		if result.Error == nil && len(result.Candles) > 0 {
+			series[result.Symbol] = result.Candles
+		}
+	}
+	return NewCorrelationMatrix(series)
+}
+
+// tailCandles copies the last n candles (so the full history can be released)
+func tailCandles(candles []Candle, n int) []Candle {
+	start := len(candles) - n
+	if start < 0 {
+		start = 0
+	}
+	return append([]Candle(nil), candles[start:]...)
+}
+
+// logReturns maps each candle's open time to its log return from the previous close
+func logReturns(candles []Candle) map[int64]float64 {
+	returns := make(map[int64]float64)
+	for i := 1; i < len(candles); i++ {
+		if candles[i-1].Close <= 0 || candles[i].Close <= 0 {
+			continue
+		}
+		returns[candles[i].OpenTime.Unix()] = math.Log(candles[i].Close / candles[i-1].Close)
+	}
+	return returns
+}
+
+// pearson returns the correlation of two return series over their common
+// timestamps and the number of observations used
+func pearson(a, b map[int64]float64) (float64, int) {
+	var sumA, sumB, sumAA, sumBB, sumAB float64
+	n := 0
+	for t, ra := range a {
+		rb, exists := b[t]
+		if !exists {
+			continue
+		}
+		n++
+		sumA += ra
+		sumB += rb
+		sumAA += ra * ra
+		sumBB += rb * rb
+		sumAB += ra * rb
+	}
+	if n < 2 {
+		return 0, n
+	}
+
+	count := float64(n)
+	cov := sumAB - sumA*sumB/count
+	varA := sumAA - sumA*sumA/count
+	varB := sumBB - sumB*sumB/count
+	if varA <= 0 || varB <= 0 {
+		return 0, n
+	}
+	return cov / math.Sqrt(varA*varB), n
+}
+
+// Get returns the correlation between two symbols. ok is false when either
+// symbol has no data or they share fewer than CORRELATION_MIN_OBSERVATIONS candles.
+func (m *CorrelationMatrix) Get(a, b string) (float64, bool) {
+	if m == nil {
+		return 0, false
+	}
+	if a == b {
+		return 1, true
+	}
+
+	key := a + "|" + b
+	if a > b {
+		key = b + "|" + a
+	}
+	if corr, exists := m.cache[key]; exists {
+		return corr, !math.IsNaN(corr)
+	}
+
+	ra, okA := m.returns[a]
+	rb, okB := m.returns[b]
+	corr, n := math.NaN(), 0
+	if okA && okB {
+		var c float64
+		c, n = pearson(ra, rb)
+		if n >= CORRELATION_MIN_OBSERVATIONS {
+			corr = c
+		}
+	}
+	m.cache[key] = corr
+	return corr, !math.IsNaN(corr)
+}
+
+// ExposureLimits caps concentration in correlated symbols and one direction
+type ExposureLimits struct {
+	ClusterThreshold          float64 // Correlation at/above this puts two symbols in one cluster
+	MaxClusterPositions       int     // Max open positions in a cluster, including the new one (0 = off)
+	MaxClusterNotionalPercent float64 // Max cluster notional as % of equity (0 = off)
+	MaxNetExposurePercent     float64 // Max |long - short| notional as % of equity (0 = off)
+	MinNotional               float64 // Downsized trades below this are rejected
+}
+
+// DefaultExposureLimits returns the configured exposure limits
+func DefaultExposureLimits() ExposureLimits {
+	return ExposureLimits{
+		ClusterThreshold:          CORRELATION_CLUSTER_THRESHOLD,
+		MaxClusterPositions:       MAX_CLUSTER_POSITIONS,
+		MaxClusterNotionalPercent: MAX_CLUSTER_NOTIONAL_PERCENT,
+		MaxNetExposurePercent:     MAX_NET_EXPOSURE_PERCENT,
+		MinNotional:               DEFAULT_MIN_NOTIONAL,
+	}
+}
+
+// signedNotional returns +size for LONG and -size for SHORT
+func signedNotional(side string, size float64) float64 {
+	if side == "SHORT" {
+		return -size
+	}
+	return size
+}
+
+// CheckExposure returns the size a new trade may take without breaching the
+// cluster and net exposure limits (0 = reject), with the reason for any change.
+// Open positions correlated with the new symbol at/above the threshold form its
+// cluster; symbols without enough shared history are treated as uncorrelated.
+func CheckExposure(symbol, side string, size, equity float64, open []*PaperTrade,
+	matrix *CorrelationMatrix, limits ExposureLimits) (float64, string) {
+	note := ""
+
+	// Correlation cluster
+	var cluster []string
+	clusterNotional := 0.0
+	for _, trade := range open {
+		corr, ok := matrix.Get(symbol, trade.Symbol)
+		if !ok || corr < limits.ClusterThreshold {
+			continue
+		}
+		cluster = append(cluster, fmt.Sprintf("%s %.2f", trade.Symbol, corr))
+		clusterNotional += trade.Size
+	}
+
+	if limits.MaxClusterPositions > 0 && len(cluster)+1 > limits.MaxClusterPositions {
+		return 0, fmt.Sprintf("correlation cluster full (%d/%d: %v)",
+			len(cluster), limits.MaxClusterPositions, cluster)
+	}
+	if limits.MaxClusterNotionalPercent > 0 && len(cluster) > 0 {
+		room := equity*limits.MaxClusterNotionalPercent/100 - clusterNotional
+		if room < size {
+			note = fmt.Sprintf("downsized $%.2f -> $%.2f for cluster notional cap %.0f%% (%v)",
+				size, math.Max(0, room), limits.MaxClusterNotionalPercent, cluster)
+			size = math.Max(0, room)
+		}
+	}
+
+	// Net directional exposure
+	if limits.MaxNetExposurePercent > 0 {
+		net := 0.0
+		for _, trade := range open {
+			net += signedNotional(trade.Side, trade.Size)
+		}
+		// Room left in the trade's direction (an opposing position frees more)
+		direction := signedNotional(side, 1)
+		room := math.Max(0, equity*limits.MaxNetExposurePercent/100-direction*net)
+		if room < size {
+			note = fmt.Sprintf("downsized $%.2f -> $%.2f for net exposure cap %.0f%% (net $%.2f)",
+				size, room, limits.MaxNetExposurePercent, net)
+			size = room
+		}
+	}
+
+	if size < limits.MinNotional || size <= 0 {
+		if note == "" {
+			note = "size below minimum notional"
+		}
+		return 0, note
+	}
+	return size, note
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ==================== CANDLE DATA QUALITY ====================
+
+// Issue severities
+const (
+	DATA_SEVERITY_ERROR   = "error"   // Corrupt data - analysis must not run on it
+	DATA_SEVERITY_WARNING = "warning" // Suspicious but usable (e.g. exchange downtime gap)
+)
+
+// DataIssue describes a single problem found in a candle series
+type DataIssue struct {
+	Severity string
+	Kind     string // "missing_interval", "duplicate", "out_of_order", "invalid_price", "high_below_low", "ohlc_inconsistent", "invalid_volume"
+	Index    int
+	Time     time.Time
+	Message  string
+}
+
+// CandleGap is a run of missing candles between two consecutive candles
+type CandleGap struct {
+	From    time.Time // Open time of the first missing candle
+	To      time.Time // Open time of the last missing candle
+	Missing int
+}
+
+// DataQualityReport collects every issue found by ValidateCandles
+type DataQualityReport struct {
+	Interval string
+	Candles  int
+	Issues   []DataIssue
+	Gaps     []CandleGap
+}
+
+// HasErrors reports whether any error-level issue was found
+func (r *DataQualityReport) HasErrors() bool {
+	return r.ErrorCount() > 0
+}
+
+// ErrorCount returns the number of error-level issues
+func (r *DataQualityReport) ErrorCount() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == DATA_SEVERITY_ERROR {
+			count++
+		}
+	}
+	return count
+}
+
+// WarningCount returns the number of warning-level issues
+func (r *DataQualityReport) WarningCount() int {
+	return len(r.Issues) - r.ErrorCount()
+}
+
+// Err returns an error summarizing error-level issues (nil if there are none)
+func (r *DataQualityReport) Err() error {
+	for _, issue := range r.Issues {
+		if issue.Severity == DATA_SEVERITY_ERROR {
+			return fmt.Errorf("data quality check failed: %d error(s), first: %s", r.ErrorCount(), issue.Message)
+		}
+	}
+	return nil
+}
+
+// Print displays the report (all issues in verbose mode, a summary otherwise)
+func (r *DataQualityReport) Print() {
+	if len(r.Issues) == 0 {
+		return
+	}
+
+	fmt.Printf("⚠️  Data quality: %d error(s), %d warning(s) in %d %s candles\n",
+		r.ErrorCount(), r.WarningCount(), r.Candles, r.Interval)
+
+	if !VERBOSE_MODE {
+		return
+	}
+
+	maxShown := 10
+	for i, issue := range r.Issues {
+		if i >= maxShown {
+			fmt.Printf("   ... and %d more\n", len(r.Issues)-maxShown)
+			break
+		}
+		icon := "⚠️ "
+		if issue.Severity == DATA_SEVERITY_ERROR {
+			icon = "❌"
+		}
+		fmt.Printf("   %s [%s] %s\n", icon, issue.Kind, issue.Message)
+	}
+}
+
+// ValidateCandles checks a kline series for gaps, duplicates, ordering and
+// invalid OHLCV values. Gap detection needs a fixed-length interval.
+func ValidateCandles(candles []Candle, interval string) *DataQualityReport {
+	report := &DataQualityReport{Interval: interval, Candles: len(candles)}
+
+	addIssue := func(severity, kind string, idx int, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, DataIssue{
+			Severity: severity,
+			Kind:     kind,
+			Index:    idx,
+			Time:     candles[idx].OpenTime,
+			Message:  fmt.Sprintf("%s: ", candles[idx].OpenTime.UTC().Format("2006-01-02 15:04")) + fmt.Sprintf(format, args...),
+		})
+	}
+
+	candleDuration, durationErr := parseIntervalDuration(interval)
+	var latest time.Time // Latest open time seen so far (out-of-order candles do not move it back)
+
+	for i, c := range candles {
+		// Price sanity (NaN marks values the fetcher could not parse)
+		prices := []float64{c.Open, c.High, c.Low, c.Close}
+		invalidPrice := false
+		for _, p := range prices {
+			if math.IsNaN(p) || math.IsInf(p, 0) || p <= 0 {
+				invalidPrice = true
+			}
+		}
+		if invalidPrice {
+			addIssue(DATA_SEVERITY_ERROR, "invalid_price", i,
+				"zero, negative or unparseable price (O: %v, H: %v, L: %v, C: %v)", c.Open, c.High, c.Low, c.Close)
+		} else if c.High < c.Low {
+			addIssue(DATA_SEVERITY_ERROR, "high_below_low", i, "high %.8f below low %.8f", c.High, c.Low)
+		} else if c.High < math.Max(c.Open, c.Close) || c.Low > math.Min(c.Open, c.Close) {
+			addIssue(DATA_SEVERITY_ERROR, "ohlc_inconsistent", i,
+				"open/close outside high-low range (O: %v, H: %v, L: %v, C: %v)", c.Open, c.High, c.Low, c.Close)
+		}
+
+		volumes := []float64{c.Volume, c.QuoteAssetVolume, c.TakerBuyBaseAssetVolume, c.TakerBuyQuoteAssetVolume}
+		for _, v := range volumes {
+			if math.IsNaN(v) || v < 0 {
+				addIssue(DATA_SEVERITY_ERROR, "invalid_volume", i, "negative or unparseable volume")
+				break
+			}
+		}
+
+		if i == 0 {
+			latest = c.OpenTime
+			continue
+		}
+
+		// Ordering, duplicates and gaps
+		switch {
+		case c.OpenTime.Equal(candles[i-1].OpenTime):
+			addIssue(DATA_SEVERITY_ERROR, "duplicate", i, "duplicate open time")
+		case c.OpenTime.Before(latest):
+			addIssue(DATA_SEVERITY_ERROR, "out_of_order", i, "candle opens before previous candle (%s)",
+				latest.UTC().Format("2006-01-02 15:04"))
+		case durationErr == nil && c.OpenTime.Sub(latest) > candleDuration:
+			missing := int(c.OpenTime.Sub(latest)/candleDuration) - 1
+			gap := CandleGap{
+				From:    latest.Add(candleDuration),
+				To:      c.OpenTime.Add(-candleDuration),
+				Missing: missing,
+			}
+			report.Gaps = append(report.Gaps, gap)
+			addIssue(DATA_SEVERITY_WARNING, "missing_interval", i, "%d missing candle(s) since %s",
+				missing, gap.From.UTC().Format("2006-01-02 15:04"))
+		}
+		if c.OpenTime.After(latest) {
+			latest = c.OpenTime
+		}
+	}
+
+	return report
+}
+
+// repairCandles sorts, de-duplicates and refetches missing or invalid candles.
+// It returns the repaired series; anything it cannot fix is left for
+// ValidateCandles to report again.
+func repairCandles(symbol, interval string, candles []Candle, report *DataQualityReport) []Candle {
+	if len(report.Issues) == 0 {
+		return candles
+	}
+
+	repaired := make([]Candle, len(candles))
+	copy(repaired, candles)
+
+	// Step 1: Restore chronological order
+	sort.SliceStable(repaired, func(i, j int) bool {
+		return repaired[i].OpenTime.Before(repaired[j].OpenTime)
+	})
+
+	// Step 2: Drop duplicates (keep the later copy - it is the fresher one)
+	deduped := repaired[:0]
+	for i, c := range repaired {
+		if i+1 < len(repaired) && repaired[i+1].OpenTime.Equal(c.OpenTime) {
+			continue
+		}
+		deduped = append(deduped, c)
+	}
+	repaired = deduped
+
+	// Step 3: Refetch gaps and candles with invalid values
+	candleDuration, err := parseIntervalDuration(interval)
+	if err != nil {
+		return repaired
+	}
+
+	type timeRange struct{ from, to time.Time }
+	var refetch []timeRange
+	for _, gap := range report.Gaps {
+		refetch = append(refetch, timeRange{gap.From, gap.To})
+	}
+	for _, issue := range report.Issues {
+		switch issue.Kind {
+		case "invalid_price", "high_below_low", "ohlc_inconsistent", "invalid_volume":
+			refetch = append(refetch, timeRange{issue.Time, issue.Time})
+		}
+	}
+	if len(refetch) == 0 {
+		return repaired
+	}
+
+	byTime := make(map[int64]Candle, len(repaired))
+	for _, c := range repaired {
+		byTime[c.OpenTime.UnixMilli()] = c
+	}
+
+	fetched := 0
+	for _, r := range refetch {
+		count := int(r.to.Sub(r.from)/candleDuration) + 1
+		if count > MAX_KLINES_PER_REQUEST {
+			count = MAX_KLINES_PER_REQUEST
+		}
+		batch, err := fetchKlinesWithParams(symbol, interval, count, r.from, r.to.Add(candleDuration-time.Millisecond))
+		if err != nil {
+			if VERBOSE_MODE {
+				fmt.Printf("⚠️  [%s] Refetch %s-%s failed: %v\n", symbol,
+					r.from.UTC().Format("15:04"), r.to.UTC().Format("15:04"), err)
+			}
+			continue
+		}
+		for _, c := range batch {
+			byTime[c.OpenTime.UnixMilli()] = c
+			fetched++
+		}
+	}
+
+	if fetched == 0 {
+		return repaired
+	}
+
+	merged := make([]Candle, 0, len(byTime))
+	for _, c := range byTime {
+		merged = append(merged, c)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].OpenTime.Before(merged[j].OpenTime)
+	})
+
+	if VERBOSE_MODE {
+		fmt.Printf("🔧 [%s] Repaired data: refetched %d candle(s)\n", symbol, fetched)
+	}
+
+	return merged
+}
+
+// checkDataQuality validates candles, attempts a repair when issues are found
+// and fails if error-level issues remain afterwards
+func checkDataQuality(symbol, interval string, candles []Candle) ([]Candle, error) {
+	if !DATA_QUALITY_CHECKS {
+		return candles, nil
+	}
+
+	report := ValidateCandles(candles, interval)
+	if len(report.Issues) == 0 {
+		return candles, nil
+	}
+
+	if DATA_QUALITY_REPAIR {
+		candles = repairCandles(symbol, interval, candles, report)
+		report = ValidateCandles(candles, interval)
+	}
+
+	report.Print()
+	if err := report.Err(); err != nil {
+		return nil, fmt.Errorf("[%s %s] %w", symbol, interval, err)
+	}
+
+	return candles, nil
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ==================== ORDER BOOK DEPTH ====================
+
+// BookLevel is one price level of an order book
+type BookLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// OrderBook is a depth snapshot, bids best (highest) first and asks best (lowest) first
+type OrderBook struct {
+	Symbol       string
+	LastUpdateID int64
+	Bids         []BookLevel
+	Asks         []BookLevel
+	Time         time.Time
+}
+
+// binanceDepthResponse is the /depth payload (also the recorded snapshot format)
+type binanceDepthResponse struct {
+	LastUpdateID int64      `json:"lastUpdateId"`
+	Bids         [][]string `json:"bids"`
+	Asks         [][]string `json:"asks"`
+}
+
+// parseBookLevels converts [price, quantity] string pairs, skipping empty levels
+func parseBookLevels(raw [][]string) []BookLevel {
+	levels := make([]BookLevel, 0, len(raw))
+	for _, level := range raw {
+		if len(level) < 2 {
+			continue
+		}
+		price, quantity := parseFilterValue(level[0]), parseFilterValue(level[1])
+		if price > 0 && quantity > 0 {
+			levels = append(levels, BookLevel{Price: price, Quantity: quantity})
+		}
+	}
+	return levels
+}
+
+// ParseOrderBook parses a Binance depth response
+func ParseOrderBook(symbol string, body []byte) (*OrderBook, error) {
+	var resp binanceDepthResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse depth for %s: %w", symbol, err)
+	}
+	return &OrderBook{
+		Symbol:       symbol,
+		LastUpdateID: resp.LastUpdateID,
+		Bids:         parseBookLevels(resp.Bids),
+		Asks:         parseBookLevels(resp.Asks),
+		Time:         time.Now(),
+	}, nil
+}
+
+// depthEndpoint returns the order book endpoint for the active market
+func depthEndpoint() string {
+	if USE_FUTURES {
+		return "/fapi/v1/depth"
+	}
+	return "/api/v3/depth"
+}
+
+// depthClient bounds /depth requests, which run while fills hold the engine locks
+var depthClient = &http.Client{Timeout: DEPTH_FETCH_TIMEOUT_SECONDS * time.Second}
+
+// FetchOrderBook downloads a depth snapshot with up to levels per side
+func FetchOrderBook(symbol string, levels int) (*OrderBook, error) {
+	url := fmt.Sprintf("%s%s?symbol=%s&limit=%d", GetBaseURL(), depthEndpoint(), symbol, levels)
+	resp, err := depthClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch depth for %s: %w", symbol, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("depth for %s: HTTP %d", symbol, resp.StatusCode)
+	}
+	return ParseOrderBook(symbol, body)
+}
+
+// LoadOrderBook reads a recorded snapshot (a saved /depth response)
+func LoadOrderBook(path, symbol string) (*OrderBook, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read depth snapshot: %w", err)
+	}
+	book, err := ParseOrderBook(symbol, body)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil {
+		book.Time = info.ModTime()
+	}
+	return book, nil
+}
+
+// Mid returns the midpoint of the best bid and ask (0 if a side is empty)
+func (ob *OrderBook) Mid() float64 {
+	if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
+		return 0
+	}
+	return (ob.Bids[0].Price + ob.Asks[0].Price) / 2
+}
+
+// Anchored returns a copy of the book moved to a new mid: each level keeps its
+// relative distance from the mid and its quote notional
+func (ob *OrderBook) Anchored(mid float64) *OrderBook {
+	current := ob.Mid()
+	if current <= 0 || mid <= 0 {
+		return ob
+	}
+
+	scale := mid / current
+	move := func(levels []BookLevel) []BookLevel {
+		moved := make([]BookLevel, len(levels))
+		for i, level := range levels {
+			moved[i] = BookLevel{Price: level.Price * scale, Quantity: level.Quantity / scale}
+		}
+		return moved
+	}
+
+	anchored := *ob
+	anchored.Bids = move(ob.Bids)
+	anchored.Asks = move(ob.Asks)
+	return &anchored
+}
+
+// DepthFill is the result of walking the book with a market order
+type DepthFill struct {
+	Requested   float64 // Quantity asked for
+	Quantity    float64 // Quantity the visible book absorbs
+	AvgPrice    float64
+	BestPrice   float64 // Top of book on the side taken
+	WorstPrice  float64 // Last level consumed
+	Levels      int     // Price levels consumed
+	SlippagePct float64 // Adverse move of AvgPrice from BestPrice
+}
+
+// Complete reports whether the whole requested quantity fills
+func (f DepthFill) Complete() bool {
+	return f.Quantity >= f.Requested*(1-1e-9)
+}
+
+// Walk simulates a market order consuming levels from the best price: buys
+// take asks, sells hit bids. Quantity beyond the visible depth is left unfilled.
+func (ob *OrderBook) Walk(side string, quantity float64) DepthFill {
+	levels := ob.Asks
+	if side == ORDER_SIDE_SELL {
+		levels = ob.Bids
+	}
+
+	fill := DepthFill{Requested: quantity}
+	if len(levels) == 0 || quantity <= 0 {
+		return fill
+	}
+	fill.BestPrice = levels[0].Price
+
+	cost := 0.0
+	remaining := quantity
+	for _, level := range levels {
+		if remaining <= 0 {
+			break
+		}
+		take := math.Min(remaining, level.Quantity)
+		cost += take * level.Price
+		fill.WorstPrice = level.Price
+		fill.Quantity += take
+		fill.Levels++
+		remaining -= take
+	}
+
+	fill.AvgPrice = cost / fill.Quantity
+	fill.SlippagePct = slippagePercent(side, fill.BestPrice, fill.AvgPrice)
+	return fill
+}
+
+// slippagePercent returns how far a fill is from the reference price against
+// the order (positive = worse: paid more on a buy, received less on a sell)
+func slippagePercent(side string, reference, price float64) float64 {
+	if reference <= 0 || price <= 0 {
+		return 0
+	}
+	if side == ORDER_SIDE_SELL {
+		return (reference - price) / reference * 100
+	}
+	return (price - reference) / reference * 100
+}
+
+// ==================== DEPTH SOURCE ====================
+
+// DepthSource supplies order book snapshots: recorded ones from SnapshotDir
+// (<dir>/<SYMBOL>.json) when set, live /depth otherwise. Recorded books are
+// re-anchored to the reference price, since they were saved at another time.
+// Live snapshots are reused for DEPTH_SNAPSHOT_MAX_AGE_SECONDS so the
+// pre-trade check and the fill see the same book.
+type DepthSource struct {
+	SnapshotDir string
+	Levels      int
+	cache       map[string]*OrderBook
+	mutex       sync.Mutex
+}
+
+// Depth fill simulation (set from flags; nil = fill at the reference price)
+var DEPTH_SOURCE *DepthSource
+
+// NewDepthSource creates a live source, or a recorded one when dir is set
+func NewDepthSource(dir string) *DepthSource {
+	return &DepthSource{
+		SnapshotDir: dir,
+		Levels:      DEPTH_LEVELS,
+		cache:       make(map[string]*OrderBook),
+	}
+}
+
+// OrderBook returns the symbol's current snapshot; recorded snapshots are
+// moved to the reference price (0 = as recorded)
+func (s *DepthSource) OrderBook(symbol string, reference float64) (*OrderBook, error) {
+	if s.SnapshotDir != "" {
+		book, err := LoadOrderBook(filepath.Join(s.SnapshotDir, symbol+".json"), symbol)
+		if err != nil {
+			return nil, err
+		}
+		return book.Anchored(reference), nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if book, exists := s.cache[symbol]; exists && time.Since(book.Time) < DEPTH_SNAPSHOT_MAX_AGE_SECONDS*time.Second {
+		return book, nil
+	}
+	book, err := FetchOrderBook(symbol, s.Levels)
+	if err != nil {
+		return nil, err
+	}
+	s.cache[symbol] = book
+	return book, nil
+}
+
+// CheckDepth walks the book for a market order of notional at price and
+// returns an error when the visible depth can't absorb it or the impact
+// exceeds MAX_DEPTH_SLIPPAGE_PERCENT
+func (s *DepthSource) CheckDepth(symbol, side string, price, notional float64) (DepthFill, error) {
+	book, err := s.OrderBook(symbol, price)
+	if err != nil {
+		return DepthFill{}, err
+	}
+
+	fill := book.Walk(side, notional/price)
+	if !fill.Complete() {
+		return fill, fmt.Errorf("book absorbs only %.2f%% of $%.2f (%d levels deep)",
+			fill.Quantity/fill.Requested*100, notional, fill.Levels)
+	}
+	if fill.SlippagePct > MAX_DEPTH_SLIPPAGE_PERCENT {
+		return fill, fmt.Errorf("slippage %.3f%% over max %.2f%% across %d levels",
+			fill.SlippagePct, MAX_DEPTH_SLIPPAGE_PERCENT, fill.Levels)
+	}
+	return fill, nil
+}
+
+// ==================== DEPTH FILLS ====================
+
+// depthMarketFill prices a paper market order by walking the symbol's book.
+// Entries fill what the visible depth absorbs and the rest expires (partial
+// is true); reduce-only exits always fill in full, sweeping the remainder at
+// the last visible level, and fall back to the reference price without a book.
+// (caller holds the lock)
+func (b *PaperBroker) depthMarketFill(req OrderRequest, quantity float64) (filled, price float64, partial bool, err error) {
+	book, err := b.Depth.OrderBook(req.Symbol, req.Price)
+	if err != nil {
+		if req.ReduceOnly {
+			return quantity, req.Price, false, nil
+		}
+		return 0, 0, false, err
+	}
+
+	walk := book.Walk(req.Side, quantity)
+	switch {
+	case walk.Complete():
+		return quantity, walk.AvgPrice, false, nil
+	case req.ReduceOnly && walk.Quantity > 0:
+		swept := quantity - walk.Quantity
+		return quantity, (walk.AvgPrice*walk.Quantity + walk.WorstPrice*swept) / quantity, false, nil
+	case req.ReduceOnly:
+		return quantity, req.Price, false, nil
+	case walk.Quantity <= 0:
+		return 0, 0, false, fmt.Errorf("no %s liquidity in the %s book", req.Side, req.Symbol)
+	}
+	return walk.Quantity, walk.AvgPrice, true, nil
+}
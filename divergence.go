@@ -67,6 +67,12 @@ type BearishDivergence struct {
 	EndRSI   float64
 }
 
+// ID identifies the divergence by its two swing times, so the same divergence
+// found again on a later scan maps to the same ID
+func (d BearishDivergence) ID() string {
+	return d.StartTime + "/" + d.EndTime
+}
+
 // findBearishDivergences identifies indices where price makes a higher high
 // but RSI makes a lower high compared to previous swing high.
 // swingLookback controls how many candles on each side define a swing high.
@@ -32,12 +32,116 @@ const (
 	SR_MAX_ZONES         = 20   // Maximum zones to track
 	SR_MAX_ZONES_DISPLAY = 10   // Maximum number of zones to display
 
+	// Volume Profile Configuration
+	VOLUME_PROFILE_ENABLED = true // Merge POC/VAH/VAL/HVN/LVN zones with pivot zones
+	VOLUME_PROFILE_WINDOW  = 300  // Candles included in the volume profile
+	VOLUME_PROFILE_BINS    = 50   // Number of price buckets in the histogram
+
+	// Trendline Configuration
+	TRENDLINES_ENABLED = true // Add unbroken trendlines and channel lines as zones
+
+	// Fibonacci Configuration
+	FIB_TARGETS_ENABLED = true // Consider Fibonacci levels as take-profit targets
+	FIB_MIN_SWING_ATR   = 3.0  // Swing must span at least this many ATRs to be measured
+
+	// Smart Money Configuration (fair value gaps & order blocks)
+	SMC_LOOKBACK                = 300  // Only keep FVGs/order blocks formed in the last N candles
+	FVG_MIN_GAP_ATR             = 0.3  // Minimum gap size as a multiple of ATR
+	OB_DISPLACEMENT_ATR         = 1.5  // Displacement candle body must be at least this many ATRs
+	SMC_TARGETS_ENABLED         = true // Consider unfilled FVGs as take-profit targets
+	SMC_STOPS_ENABLED           = true // Place stops beyond a nearby order block
+	SMC_OB_MAX_DISTANCE_PERCENT = 1.0  // Order block must start within this % of entry
+	SMC_STOP_BUFFER_ATR         = 0.1  // Extra stop distance beyond the order block (x ATR)
+
+	// Multi-Timeframe Configuration
+	MTF_DIVERGENCE_LOOKBACK    = 10   // HTF divergence counts if it ended within this many HTF candles
+	MTF_ZONE_PROXIMITY_PERCENT = 0.2  // Price within this % of a HTF zone counts as "at" the zone
+	MIN_MTF_CONFLUENCE_SCORE   = 40.0 // Minimum confluence score to take a trade when --mtf is set
+
+	// Resampling Configuration
+	RESAMPLE_INCLUDE_PARTIAL = true // Keep the still-forming trailing bucket (matches live klines)
+
+	// Data Quality Configuration
+	DATA_QUALITY_CHECKS = true // Validate klines (gaps, duplicates, ordering, bad prices) before analysis
+	DATA_QUALITY_REPAIR = true // Sort, de-duplicate and refetch gaps/invalid candles before failing
+
+	// Candlestick Pattern Configuration
+	PATTERN_LOOKBACK               = 3   // Pattern must complete within the last N candles
+	PATTERN_MIN_STRENGTH           = 0.5 // Minimum pattern strength (0-1) to confirm an entry
+	PATTERN_ZONE_PROXIMITY_PERCENT = 0.2 // Pattern high within this % of a zone counts as a test
+
+	// Signal Score Configuration (weights live in SIGNAL_SCORE_CONFIG)
+	MIN_SIGNAL_SCORE        = 0.0 // Minimum 0-100 confidence score to take a trade (0 = disabled)
+	SCORE_SIZING_MIN_FACTOR = 0.5 // Size multiplier at score 0 (score 100 = full size)
+
+	// Slot Allocation Configuration (multi-symbol paper trading)
+	REPLACE_MIN_SCORE_MARGIN = 30.0 // New signal must outscore the weakest position by this much to replace it
+
+	// Re-entry Cooldown Configuration (per symbol, after a position closes)
+	COOLDOWN_CANDLES_AFTER_WIN  = 3  // Candles to wait after a winning close
+	COOLDOWN_CANDLES_AFTER_LOSS = 10 // Candles to wait after a losing close
+	COOLDOWN_MINUTES_AFTER_WIN  = 0  // Minimum wait in minutes after a win (the longer of the two applies)
+	COOLDOWN_MINUTES_AFTER_LOSS = 0  // Minimum wait in minutes after a loss (the longer of the two applies)
+
+	// Session Filter Configuration (windows are set from flags)
+	FUNDING_BLACKOUT_MINUTES  = 30 // Block entries this many minutes either side of funding (--block-funding)
+	CALENDAR_BLACKOUT_MINUTES = 30 // Default blackout before/after a calendar event
+
+	// Portfolio Risk Limits (multi-symbol paper trading, see RiskConfig)
+	MAX_DAILY_LOSS_PERCENT    = 5.0  // Halt when equity drops this % from the UTC day's start
+	MAX_DRAWDOWN_PERCENT      = 15.0 // Halt when equity drops this % from its peak
+	MAX_CONSECUTIVE_LOSSES    = 5    // Halt after this many losing closes in a row
+	RISK_RESUME_AFTER_MINUTES = 240  // Wait before resuming for limits using the "after" rule
+
 	// Risk Management
 	RISK_REWARD_RATIO   = 1.5 // Lower R/R acceptable for high-frequency scalping
 	MAX_RISK_PERCENT    = 1.0 // Reduce risk per trade (more trades = aggregate risk)
 	STOP_LOSS_PERCENT   = 0.4 // Tight stop for 1m (~$400 on BTC at $100k)
 	TAKE_PROFIT_PERCENT = 0.8 // Realistic 1m target (~$800 on BTC at $100k)
 
+	// Position Sizing (mode set with --sizing, see position_sizing.go)
+	MAX_LEVERAGE                  = 1.0   // Total open notional <= equity x this
+	MAX_POSITION_NOTIONAL_PERCENT = 100.0 // Single position notional <= this % of equity
+	DEFAULT_MIN_NOTIONAL          = 5.0   // Skip orders below the exchange minimum (USDT)
+	SIZING_ATR_MULTIPLE           = 2.0   // ATR sizer: risk MAX_RISK_PERCENT over this many ATRs
+	KELLY_FRACTION                = 0.5   // Kelly sizer: use half Kelly
+	KELLY_MIN_TRADES              = 20    // Kelly sizer: closed trades needed before leaving the fallback
+	KELLY_LOOKBACK                = 50    // Kelly sizer: rolling window of closed trades
+
+	// Exchange Filters (see exchange_filters.go)
+	ENFORCE_EXCHANGE_FILTERS    = true // Round prices/sizes to tick and lot size, reject below minimums
+	EXCHANGE_FILTER_TTL_MINUTES = 60   // Refresh cached exchange info after this long
+
+	// Depth Fills (--depth-fills / --depth-dir, see depth.go)
+	DEPTH_LEVELS                   = 100 // Levels per side fetched from /depth
+	DEPTH_SNAPSHOT_MAX_AGE_SECONDS = 5   // Reuse a live snapshot for this long
+	DEPTH_FETCH_TIMEOUT_SECONDS    = 5   // Give up on a /depth request after this long
+	MAX_DEPTH_SLIPPAGE_PERCENT     = 0.5 // Reject entries whose book impact exceeds this
+
+	// Limit Entries (--entry-order limit, see order_matching.go)
+	LIMIT_ENTRY_MAX_DISTANCE_PERCENT = 1.0 // Only rest at a zone edge within this % above price
+	LIMIT_ENTRY_EXPIRY_CANDLES       = 3   // Cancel unfilled limit entries after this many candles
+
+	// Scale-In (--scale-in, see scale_in.go)
+	SCALE_IN_ADD_PERCENT              = 50.0 // Each add is this % of the first leg
+	SCALE_IN_MAX_ADDS                 = 1    // Adds allowed per position
+	SCALE_IN_RETEST_TOLERANCE_PERCENT = 0.1  // A high within this % under ZoneBot counts as a retest
+
+	// Time Stops (--max-hold, --min-profit-by, --flat-window)
+	FLAT_WINDOW_LEAD_MINUTES = 5 // Close positions (and stop entering) this long before a flat window
+
+	// Trailing Stops (--tm-profile chandelier/swing/psar, see internal/trademanager/trailing.go)
+	TRAIL_BARS       = 100 // Recent candles fed to the trade manager
+	TRAIL_ATR_PERIOD = 14  // ATR period for the Chandelier trail
+
+	// Correlation Exposure (multi-symbol, see correlation.go)
+	CORRELATION_LOOKBACK          = 100  // Candles of returns used for correlation
+	CORRELATION_MIN_OBSERVATIONS  = 30   // Shared returns needed before a pair counts as correlated
+	CORRELATION_CLUSTER_THRESHOLD = 0.8  // Symbols correlated at/above this share a cluster
+	MAX_CLUSTER_POSITIONS         = 2    // Open positions per correlation cluster
+	MAX_CLUSTER_NOTIONAL_PERCENT  = 40.0 // Cluster notional <= this % of equity
+	MAX_NET_EXPOSURE_PERCENT      = 75.0 // |long - short| notional <= this % of equity
+
 	// Analysis Settings
 	MIN_DIVERGENCES_FOR_SIGNAL = 1  // Minimum divergences needed for a signal
 	DIVERGENCE_STRENGTH_HIGH   = 10 // RSI difference % for strong divergence
@@ -90,15 +194,31 @@ func SetQuietMode(quiet bool) {
 
 // TradingEngine orchestrates all analysis components
 type TradingEngine struct {
-	Symbol      string
-	Interval    string
-	Limit       int
-	Candles     []Candle
-	RSI         []float64
-	ATR         []float64
-	Divergences []BearishDivergence
-	SRZones     []SRZone
-	SRConfig    SRConfig
+	Symbol         string
+	Interval       string
+	SourceInterval string // Fetch this interval and resample to Interval (empty = fetch directly)
+	Limit          int
+	BarConfig      BarConfig // Bar type built from the fetched klines
+	RawCandles     []Candle  // Time-based klines as fetched (before bar building)
+	Candles        []Candle
+	RSI            []float64
+	ATR            []float64
+	Divergences    []BearishDivergence
+	SRZones        []SRZone
+	SRConfig       SRConfig
+	Patterns       []CandlePattern
+	Regime         *MarketRegime
+
+	Trendlines      []Trendline
+	Channels        []Channel
+	TrendlineBreaks []TrendlineBreak
+
+	FibSwing  *FibSwing
+	FibLevels []FibLevel
+
+	SMCZones []SMCZone
+
+	VolumeProfile *VolumeProfile
 }
 
 // ==================== ENGINE METHODS ====================
@@ -125,27 +245,75 @@ func NewTradingEngine(symbol, interval string, limit int) *TradingEngine {
 		AlignZones:     ALIGN_ZONES,
 		MinStrength:    SR_MIN_STRENGTH,
 		MaxZones:       SR_MAX_ZONES,
+
+		UseVolumeProfile:    VOLUME_PROFILE_ENABLED,
+		VolumeProfileWindow: VOLUME_PROFILE_WINDOW,
+		VolumeProfileBins:   VOLUME_PROFILE_BINS,
+		UseTrendlines:       TRENDLINES_ENABLED,
 	}
 
 	return &TradingEngine{
-		Symbol:   symbol,
-		Interval: interval,
-		Limit:    limit,
-		SRConfig: srConfig,
+		Symbol:         symbol,
+		Interval:       interval,
+		SourceInterval: resolveSourceInterval(interval, SOURCE_INTERVAL),
+		Limit:          limit,
+		BarConfig:      BAR_CONFIG,
+		SRConfig:       srConfig,
 	}
 }
 
 // FetchData retrieves candle data from Binance
 func (e *TradingEngine) FetchData() error {
-	fmt.Printf("🔄 Fetching %s data for %s (limit: %d)...\n", e.Interval, e.Symbol, e.Limit)
-
-	candles, err := fetchKlines(e.Symbol, e.Interval, e.Limit)
+	var candles []Candle
+	var err error
+
+	fetchInterval := e.Interval
+	if e.SourceInterval != "" {
+		fetchInterval = e.SourceInterval
+		fmt.Printf("🔄 Building %s data for %s from %s candles (limit: %d)...\n", e.Interval, e.Symbol, e.SourceInterval, e.Limit)
+		needed, neededErr := sourceCandlesNeeded(e.Interval, e.SourceInterval, e.Limit)
+		if neededErr != nil {
+			return fmt.Errorf("failed to fetch data: %w", neededErr)
+		}
+		candles, err = fetchKlinesPaged(e.Symbol, e.SourceInterval, needed)
+	} else {
+		fmt.Printf("🔄 Fetching %s data for %s (limit: %d)...\n", e.Interval, e.Symbol, e.Limit)
+		candles, err = fetchKlines(e.Symbol, e.Interval, e.Limit)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to fetch data: %w", err)
 	}
+	if len(candles) == 0 {
+		return fmt.Errorf("no candles returned for %s %s", e.Symbol, e.Interval)
+	}
+
+	// Validate (and repair) the raw klines before anything is derived from them
+	candles, err = checkDataQuality(e.Symbol, fetchInterval, candles)
+	if err != nil {
+		return err
+	}
 
-	e.Candles = candles
-	fmt.Printf("✅ Fetched %d candles\n", len(e.Candles))
+	if e.SourceInterval != "" {
+		candles, err = resampleToLimit(candles, e.Interval, e.Limit)
+		if err != nil {
+			return fmt.Errorf("failed to resample %s candles to %s: %w", e.SourceInterval, e.Interval, err)
+		}
+	}
+
+	e.RawCandles = candles
+	fmt.Printf("✅ Fetched %d candles\n", len(candles))
+
+	bars, err := BuildBars(candles, e.BarConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build %s bars: %w", e.BarConfig.Type, err)
+	}
+	if len(bars) == 0 {
+		return fmt.Errorf("%s bar builder produced no bars from %d candles", e.BarConfig.Type, len(candles))
+	}
+	e.Candles = bars
+	if e.BarConfig.Type != "" && e.BarConfig.Type != BAR_TYPE_TIME {
+		fmt.Printf("✅ Built %d %s bars\n", len(e.Candles), e.BarConfig.Type)
+	}
 
 	if VERBOSE_MODE {
 		fmt.Printf("   First candle: %s (O: %.2f, H: %.2f, L: %.2f, C: %.2f)\n",
@@ -160,6 +328,18 @@ func (e *TradingEngine) FetchData() error {
 	return nil
 }
 
+// LastPrice returns the latest traded price. Synthetic bars (e.g. Heikin-Ashi)
+// do not close at real prices, so this reads the raw klines when available.
+func (e *TradingEngine) LastPrice() float64 {
+	if len(e.RawCandles) > 0 {
+		return e.RawCandles[len(e.RawCandles)-1].Close
+	}
+	if len(e.Candles) > 0 {
+		return e.Candles[len(e.Candles)-1].Close
+	}
+	return 0
+}
+
 // CalculateIndicators computes RSI and other technical indicators
 func (e *TradingEngine) CalculateIndicators() {
 	fmt.Printf("\n📊 Calculating technical indicators...\n")
@@ -186,6 +366,10 @@ func (e *TradingEngine) CalculateIndicators() {
 	} else if currentRSI < 30 {
 		fmt.Printf("   ⚠️  RSI is OVERSOLD (%.2f < 30)\n", currentRSI)
 	}
+
+	// Classify market regime
+	e.DetectRegime()
+	e.printRegime()
 }
 
 // FindDivergences identifies bearish divergences
@@ -209,15 +393,30 @@ func (e *TradingEngine) IdentifySupportResistance() {
 	if e.SRConfig.AlignZones {
 		fmt.Printf("   Zone alignment: ENABLED (merging overlapping zones)\n")
 	}
+	if e.SRConfig.UseVolumeProfile {
+		fmt.Printf("   Volume profile: ENABLED (%d candles, %d bins)\n", e.SRConfig.VolumeProfileWindow, e.SRConfig.VolumeProfileBins)
+	}
 
 	// Use the advanced S/R detection matching TradingView
-	e.SRZones = findAdvancedSupportResistance(e.Candles, e.SRConfig)
+	e.VolumeProfile = srVolumeProfile(e.Candles, e.SRConfig)
+	e.SRZones = findAdvancedSupportResistance(e.Candles, e.SRConfig, e.VolumeProfile)
 
 	fmt.Printf("✅ Found %d significant zone(s)\n", len(e.SRZones))
 
+	if e.SRConfig.UseTrendlines {
+		e.IdentifyTrendlines()
+	}
+	e.IdentifyFibonacci()
+	e.IdentifySmartMoney()
+
+	if e.VolumeProfile != nil && SHOW_SR_ZONES {
+		fmt.Printf("   📊 Volume Profile: POC $%.2f | VAH $%.2f | VAL $%.2f | %d HVN / %d LVN\n",
+			e.VolumeProfile.POC, e.VolumeProfile.VAH, e.VolumeProfile.VAL,
+			len(e.VolumeProfile.HVNs), len(e.VolumeProfile.LVNs))
+	}
+
 	if len(e.SRZones) > 0 && SHOW_SR_ZONES {
-		currentPrice := e.Candles[len(e.Candles)-1].Close
-		e.printSupportResistanceZones(currentPrice)
+		e.printSupportResistanceZones(e.LastPrice())
 	}
 }
 
@@ -230,94 +429,70 @@ func (e *TradingEngine) GenerateTradeSignals() {
 	fmt.Printf("\n💡 TRADE SIGNAL ANALYSIS\n")
 	fmt.Println("==========================================")
 
-	currentPrice := e.Candles[len(e.Candles)-1].Close
+	currentPrice := e.LastPrice()
 	currentTime := e.Candles[len(e.Candles)-1].OpenTime
 
-	// Check for recent divergences
-	recentDivergences := 0
-	for _, div := range e.Divergences {
-		divTime, _ := time.Parse("2006-01-02 15:04", div.EndTime)
-		hoursSince := time.Since(divTime).Hours()
-
-		if hoursSince < 72 { // Within last 72 hours (3 days for 4h timeframe)
-			recentDivergences++
-		}
-	}
-
-	// Find nearest resistance
-	var nearestResistance *SRZone
-	minDistanceUp := 1000000.0
-
-	for i := range e.SRZones {
-		if e.SRZones[i].Level > currentPrice {
-			distance := e.SRZones[i].Level - currentPrice
-			if distance < minDistanceUp {
-				minDistanceUp = distance
-				nearestResistance = &e.SRZones[i]
-			}
-		}
-	}
-
-	// Find nearest support
-	var nearestSupport *SRZone
-	minDistanceDown := 1000000.0
+	// Check for recent divergences (72h = 3 days for 4h timeframe)
+	recentDivergences, _, _ := recentDivergenceStats(e.Divergences, SIGNAL_SCORE_CONFIG.RecencyHours)
 
-	for i := range e.SRZones {
-		if e.SRZones[i].Level < currentPrice {
-			distance := currentPrice - e.SRZones[i].Level
-			if distance < minDistanceDown {
-				minDistanceDown = distance
-				nearestSupport = &e.SRZones[i]
-			}
-		}
-	}
+	// Find nearest resistance and support
+	nearestResistance, nearestSupport := e.nearestZones(currentPrice)
 
 	// Generate signal
 	signal := "NEUTRAL"
 	strength := "WEAK"
 
+	// Stop: above the resistance zone top, an order block or the fixed percentage
+	entry := currentPrice
+	stopLoss, slMethod := e.selectStopLoss("SHORT", entry, nearestResistance)
+
+	// Target: support zone bottom, a Fibonacci level or the fixed percentage
+	takeProfit, tpMethod := e.selectTakeProfit("SHORT", entry, stopLoss, nearestSupport)
+
+	risk := stopLoss - entry
+	reward := entry - takeProfit
+	rr := reward / risk
+	score := e.ScoreShortSignal(rr, nearestResistance)
+
 	if recentDivergences >= MIN_DIVERGENCES_FOR_SIGNAL {
 		signal = "BEARISH"
-		if recentDivergences >= 2 {
-			strength = "STRONG"
-		} else {
-			strength = "MEDIUM"
-		}
+		strength = score.Label()
+	}
+
+	regimeBlocked := signal == "BEARISH" && !strategyEnabled(STRATEGY_DIVERGENCE_FADE, e.CurrentRegime())
+	scoreBlocked := signal == "BEARISH" && !regimeBlocked && !score.MeetsMinimum()
+	if regimeBlocked || scoreBlocked {
+		signal = "NEUTRAL"
+		strength = "WEAK"
 	}
 
 	fmt.Printf("📍 Current Price: $%.2f (%s)\n", currentPrice, currentTime.Format("2006-01-02 15:04"))
 	fmt.Printf("📊 Current RSI: %.2f\n", e.RSI[len(e.RSI)-1])
 	fmt.Printf("🔔 Signal: %s (%s)\n", signal, strength)
-	fmt.Printf("📈 Recent Divergences (72h): %d\n\n", recentDivergences)
+	fmt.Printf("📈 Recent Divergences (72h): %d\n", recentDivergences)
+	fmt.Printf("🧭 Market Regime: %s\n", e.CurrentRegime())
+	if regimeBlocked {
+		fmt.Printf("   ⏸️  Divergence signal ignored - strategy disabled in %s regime\n", e.CurrentRegime())
+	}
+	if recentDivergences >= MIN_DIVERGENCES_FOR_SIGNAL {
+		printSignalScore(score)
+	}
+	if scoreBlocked {
+		fmt.Printf("   ⏸️  Divergence signal ignored - score %.0f below minimum %.0f\n", score.Total, MIN_SIGNAL_SCORE)
+	}
+	e.DetectPatterns()
+	e.printPatterns()
+	fmt.Println()
 
 	if signal == "BEARISH" {
 		fmt.Println("🎯 SUGGESTED SHORT TRADE SETUP:")
 		fmt.Println("─────────────────────────────────────────")
 
-		entry := currentPrice
-		var stopLoss, takeProfit float64
-
-		if nearestResistance != nil {
-			stopLoss = nearestResistance.ZoneTop // Stop above the zone top
-		} else {
-			stopLoss = currentPrice * (1 + STOP_LOSS_PERCENT/100)
-		}
-
-		if nearestSupport != nil {
-			takeProfit = nearestSupport.ZoneBot // Target below the zone bottom
-		} else {
-			takeProfit = currentPrice * (1 - TAKE_PROFIT_PERCENT/100)
-		}
-
-		risk := stopLoss - entry
-		reward := entry - takeProfit
-		rr := reward / risk
-
 		fmt.Printf("  Entry:        $%.2f\n", entry)
-		fmt.Printf("  Stop Loss:    $%.2f (%.2f%% above entry)\n",
-			stopLoss, ((stopLoss-entry)/entry)*100)
-		fmt.Printf("  Take Profit:  $%.2f (%.2f%% below entry)\n",
-			takeProfit, ((entry-takeProfit)/entry)*100)
+		fmt.Printf("  Stop Loss:    $%.2f (%.2f%% above entry, %s)\n",
+			stopLoss, ((stopLoss-entry)/entry)*100, slMethod)
+		fmt.Printf("  Take Profit:  $%.2f (%.2f%% below entry, %s)\n",
+			takeProfit, ((entry-takeProfit)/entry)*100, tpMethod)
 		fmt.Printf("  Risk/Reward:  %.2f:1\n", rr)
 
 		if rr >= RISK_REWARD_RATIO {
@@ -326,13 +501,24 @@ func (e *TradingEngine) GenerateTradeSignals() {
 			fmt.Printf("  ⚠️  R/R ratio below minimum (required: %.1f:1)\n", RISK_REWARD_RATIO)
 		}
 
+		if REQUIRE_PATTERN_CONFIRMATION {
+			if pattern, zone := e.BearishPatternAtResistance(); pattern != nil {
+				fmt.Printf("  ✅ Confirmed by %s (strength %.2f) at $%.2f-$%.2f\n",
+					pattern.Name, pattern.Strength, zone.ZoneBot, zone.ZoneTop)
+			} else {
+				fmt.Println("  ⚠️  No bearish candle pattern at resistance yet")
+			}
+		}
+
 		fmt.Println("\n  Position Sizing (example $10,000 account):")
 		accountSize := 10000.0
 		riskAmount := accountSize * (MAX_RISK_PERCENT / 100)
 		riskPercentPrice := ((stopLoss - entry) / entry) * 100
-		positionSize := riskAmount / (riskPercentPrice / 100 * entry)
+		positionSize := riskAmount / (riskPercentPrice / 100 * entry) * score.SizeFactor()
+		riskAmount *= score.SizeFactor()
 
-		fmt.Printf("    Max Risk:     $%.2f (%.1f%% of account)\n", riskAmount, MAX_RISK_PERCENT)
+		fmt.Printf("    Max Risk:     $%.2f (%.1f%% of account x %.2f score factor)\n",
+			riskAmount, MAX_RISK_PERCENT, score.SizeFactor())
 		fmt.Printf("    Position:     $%.2f (%.4f %s)\n",
 			positionSize*entry, positionSize, e.Symbol[:len(e.Symbol)-4])
 		fmt.Printf("    Potential P/L: -$%.2f / +$%.2f\n", riskAmount, riskAmount*rr)
@@ -459,8 +645,8 @@ func (e *TradingEngine) printSupportResistanceZones(currentPrice float64) {
 
 			zoneWidth := ((zone.ZoneTop - zone.ZoneBot) / zone.Level) * 100
 
-			fmt.Printf("  R%d: $%.2f [%.2f - %.2f] %s\n",
-				i+1, zone.Level, zone.ZoneBot, zone.ZoneTop, strengthLabel)
+			fmt.Printf("  R%d: $%.2f [%.2f - %.2f] %s%s\n",
+				i+1, zone.Level, zone.ZoneBot, zone.ZoneTop, strengthLabel, zoneSourceLabel(zone))
 			fmt.Printf("      Strength: %d pivots | Width: %.2f%% | Distance: +%.2f%%\n",
 				zone.PivotCount, zoneWidth, distance)
 
@@ -498,8 +684,8 @@ func (e *TradingEngine) printSupportResistanceZones(currentPrice float64) {
 
 			zoneWidth := ((zone.ZoneTop - zone.ZoneBot) / zone.Level) * 100
 
-			fmt.Printf("  S%d: $%.2f [%.2f - %.2f] %s\n",
-				i+1, zone.Level, zone.ZoneBot, zone.ZoneTop, strengthLabel)
+			fmt.Printf("  S%d: $%.2f [%.2f - %.2f] %s%s\n",
+				i+1, zone.Level, zone.ZoneBot, zone.ZoneTop, strengthLabel, zoneSourceLabel(zone))
 			fmt.Printf("      Strength: %d pivots | Width: %.2f%% | Distance: -%.2f%%\n",
 				zone.PivotCount, zoneWidth, distance)
 
@@ -518,6 +704,14 @@ func (e *TradingEngine) printSupportResistanceZones(currentPrice float64) {
 	fmt.Println()
 }
 
+// zoneSourceLabel returns a suffix naming non-pivot zone sources (e.g. " (vpoc)")
+func zoneSourceLabel(zone SRZone) string {
+	if zone.Type == "support" || zone.Type == "resistance" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", zone.Type)
+}
+
 // ==================== TIMEZONE HELPERS ====================
 
 // getIST returns the current time in IST
@@ -564,6 +758,10 @@ func (e *TradingEngine) parseCandleDuration() time.Duration {
 	case "1w":
 		return 7 * 24 * time.Hour
 	default:
+		// Custom intervals (e.g. 7m, 90m) built by the resampler
+		if d, err := parseIntervalDuration(e.Interval); err == nil {
+			return d
+		}
 		return 4 * time.Hour
 	}
 }
@@ -802,6 +1000,12 @@ func (e *OptimizedEngine) RunParallel() error {
 	// Wait for all indicators to complete
 	wg.Wait()
 
+	// Classify market regime (reads raw klines, independent of RSI/ATR)
+	e.DetectRegime()
+	if VERBOSE_MODE {
+		e.printRegime()
+	}
+
 	if VERBOSE_MODE {
 		fmt.Printf("\n✅ All indicators calculated (RSI: %v, ATR: %v)\n\n", rsiDone, atrDone)
 		fmt.Println("⚡ Starting parallel analysis...")
@@ -837,7 +1041,7 @@ func (e *OptimizedEngine) RunParallel() error {
 	wg.Wait()
 
 	if VERBOSE_MODE {
-		fmt.Println("\n✅ All parallel analyses completed\n")
+		fmt.Print("\n✅ All parallel analyses completed\n\n")
 	}
 
 	// Step 4: Generate signals (needs all previous data)
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ==================== EXCHANGE FILTERS ====================
+
+// BinanceSymbolFilter is one entry of a symbol's "filters" array. Fields are
+// strings as sent by Binance; only the ones for the filter type are set.
+type BinanceSymbolFilter struct {
+	FilterType  string `json:"filterType"`
+	MinPrice    string `json:"minPrice"`
+	MaxPrice    string `json:"maxPrice"`
+	TickSize    string `json:"tickSize"`
+	MinQty      string `json:"minQty"`
+	MaxQty      string `json:"maxQty"`
+	StepSize    string `json:"stepSize"`
+	MinNotional string `json:"minNotional"` // Spot MIN_NOTIONAL / NOTIONAL
+	Notional    string `json:"notional"`    // Futures MIN_NOTIONAL
+}
+
+// SymbolFilters holds the order rules for one symbol (0 = not set)
+type SymbolFilters struct {
+	Symbol string
+
+	// PRICE_FILTER
+	TickSize float64
+	MinPrice float64
+	MaxPrice float64
+
+	// LOT_SIZE (limit orders)
+	StepSize float64
+	MinQty   float64
+	MaxQty   float64
+
+	// MARKET_LOT_SIZE (market orders)
+	MarketStepSize float64
+	MarketMinQty   float64
+	MarketMaxQty   float64
+
+	// MIN_NOTIONAL / NOTIONAL
+	MinNotional float64
+}
+
+// parseFilterValue parses a filter string, treating blanks and errors as 0
+func parseFilterValue(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// ParseSymbolFilters extracts the filters we enforce from exchange info
+func ParseSymbolFilters(info BinanceSymbolInfo) SymbolFilters {
+	f := SymbolFilters{Symbol: info.Symbol}
+	for _, filter := range info.Filters {
+		switch filter.FilterType {
+		case "PRICE_FILTER":
+			f.TickSize = parseFilterValue(filter.TickSize)
+			f.MinPrice = parseFilterValue(filter.MinPrice)
+			f.MaxPrice = parseFilterValue(filter.MaxPrice)
+		case "LOT_SIZE":
+			f.StepSize = parseFilterValue(filter.StepSize)
+			f.MinQty = parseFilterValue(filter.MinQty)
+			f.MaxQty = parseFilterValue(filter.MaxQty)
+		case "MARKET_LOT_SIZE":
+			f.MarketStepSize = parseFilterValue(filter.StepSize)
+			f.MarketMinQty = parseFilterValue(filter.MinQty)
+			f.MarketMaxQty = parseFilterValue(filter.MaxQty)
+		case "MIN_NOTIONAL", "NOTIONAL":
+			minNotional := parseFilterValue(filter.MinNotional)
+			if minNotional == 0 {
+				minNotional = parseFilterValue(filter.Notional)
+			}
+			if minNotional > f.MinNotional {
+				f.MinNotional = minNotional
+			}
+		}
+	}
+	return f
+}
+
+// Rounding directions for roundToStep
+const (
+	ROUND_NEAREST = "nearest"
+	ROUND_DOWN    = "down"
+	ROUND_UP      = "up"
+)
+
+// roundToStep rounds value to a multiple of step, trimming float noise to the
+// step's precision. A step of 0 leaves the value unchanged.
+func roundToStep(value, step float64, mode string) float64 {
+	if step <= 0 {
+		return value
+	}
+
+	units := value / step
+	switch mode {
+	case ROUND_DOWN:
+		units = math.Floor(units + 1e-9)
+	case ROUND_UP:
+		units = math.Ceil(units - 1e-9)
+	default:
+		units = math.Round(units)
+	}
+
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step) - 1e-9))
+	}
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(units*step*scale) / scale
+}
+
+// RoundPrice rounds a price to the tick size
+func (f *SymbolFilters) RoundPrice(price float64) float64 {
+	if f == nil {
+		return price
+	}
+	return roundToStep(price, f.TickSize, ROUND_NEAREST)
+}
+
+// RoundQuantity rounds a market order quantity down to the lot step
+func (f *SymbolFilters) RoundQuantity(quantity float64) float64 {
+	if f == nil {
+		return quantity
+	}
+	step, _, _ := f.marketLot()
+	return roundToStep(quantity, step, ROUND_DOWN)
+}
+
+// marketLot returns the step and bounds that apply to market orders
+// (MARKET_LOT_SIZE where set, LOT_SIZE otherwise)
+func (f *SymbolFilters) marketLot() (step, minQty, maxQty float64) {
+	step, minQty, maxQty = f.StepSize, f.MinQty, f.MaxQty
+	if f.MarketStepSize > 0 {
+		step = f.MarketStepSize
+	}
+	if f.MarketMinQty > 0 {
+		minQty = f.MarketMinQty
+	}
+	if f.MarketMaxQty > 0 {
+		maxQty = f.MarketMaxQty
+	}
+	return step, minQty, maxQty
+}
+
+// RoundedOrder is an order adjusted to the exchange filters
+type RoundedOrder struct {
+	Entry      float64
+	StopLoss   float64
+	TakeProfit float64
+	Quantity   float64 // Base asset quantity
+	Notional   float64 // Quantity x entry
+}
+
+// RoundOrder rounds a market entry's prices to the tick size and its notional
+// to a whole number of lot steps (rounded down so it never grows). Returns an
+// error naming the filter when the order would be rejected.
+func (f *SymbolFilters) RoundOrder(side string, entry, stopLoss, takeProfit, notional float64) (RoundedOrder, error) {
+	if entry <= 0 {
+		return RoundedOrder{}, fmt.Errorf("invalid entry price %.8f", entry)
+	}
+	if f == nil {
+		return RoundedOrder{Entry: entry, StopLoss: stopLoss, TakeProfit: takeProfit,
+			Quantity: notional / entry, Notional: notional}, nil
+	}
+
+	order := RoundedOrder{
+		Entry:      f.RoundPrice(entry),
+		StopLoss:   f.RoundPrice(stopLoss),
+		TakeProfit: f.RoundPrice(takeProfit),
+	}
+
+	// Keep the stop on the losing side of entry after rounding
+	if side == "SHORT" && order.StopLoss <= order.Entry {
+		order.StopLoss = roundToStep(order.Entry+f.TickSize, f.TickSize, ROUND_UP)
+	} else if side == "LONG" && order.StopLoss >= order.Entry {
+		order.StopLoss = roundToStep(order.Entry-f.TickSize, f.TickSize, ROUND_DOWN)
+	}
+
+	if f.MinPrice > 0 && order.Entry < f.MinPrice {
+		return order, fmt.Errorf("PRICE_FILTER: price %.8f below min %.8f", order.Entry, f.MinPrice)
+	}
+	if f.MaxPrice > 0 && order.Entry > f.MaxPrice {
+		return order, fmt.Errorf("PRICE_FILTER: price %.8f above max %.8f", order.Entry, f.MaxPrice)
+	}
+
+	step, minQty, maxQty := f.marketLot()
+	order.Quantity = roundToStep(notional/order.Entry, step, ROUND_DOWN)
+	if maxQty > 0 && order.Quantity > maxQty {
+		order.Quantity = roundToStep(maxQty, step, ROUND_DOWN)
+	}
+	if order.Quantity <= 0 || (minQty > 0 && order.Quantity < minQty) {
+		return order, fmt.Errorf("LOT_SIZE: quantity %.8f below min %.8f (step %.8f)",
+			notional/order.Entry, minQty, step)
+	}
+
+	order.Notional = order.Quantity * order.Entry
+	if f.MinNotional > 0 && order.Notional < f.MinNotional {
+		return order, fmt.Errorf("MIN_NOTIONAL: $%.2f below min $%.2f", order.Notional, f.MinNotional)
+	}
+
+	return order, nil
+}
+
+// ExchangeFilterCache holds SymbolFilters for every symbol of the active market,
+// loaded from exchange info on first use and refreshed after EXCHANGE_FILTER_TTL_MINUTES
+type ExchangeFilterCache struct {
+	filters  map[string]*SymbolFilters
+	loadedAt time.Time
+	lastErr  error
+	mutex    sync.Mutex
+}
+
+// EXCHANGE_FILTERS is the shared cache used by the paper engines
+var EXCHANGE_FILTERS = NewExchangeFilterCache()
+
+// NewExchangeFilterCache creates an empty cache
+func NewExchangeFilterCache() *ExchangeFilterCache {
+	return &ExchangeFilterCache{filters: make(map[string]*SymbolFilters)}
+}
+
+// Store replaces the cached filters with those from exchange info
+func (c *ExchangeFilterCache) Store(info BinanceExchangeInfo) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.store(info)
+}
+
+// store replaces the cached filters (caller holds the lock)
+func (c *ExchangeFilterCache) store(info BinanceExchangeInfo) {
+	c.filters = make(map[string]*SymbolFilters, len(info.Symbols))
+	for _, s := range info.Symbols {
+		f := ParseSymbolFilters(s)
+		c.filters[s.Symbol] = &f
+	}
+	c.loadedAt = time.Now()
+	c.lastErr = nil
+}
+
+// Get returns the filters for a symbol, fetching exchange info if the cache is
+// empty or stale. Returns nil if they can't be loaded (orders go unrounded).
+func (c *ExchangeFilterCache) Get(symbol string) *SymbolFilters {
+	if !ENFORCE_EXCHANGE_FILTERS {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if time.Since(c.loadedAt) > EXCHANGE_FILTER_TTL_MINUTES*time.Minute {
+		info, err := fetchExchangeInfo()
+		if err != nil {
+			if c.lastErr == nil {
+				fmt.Printf("⚠️  Exchange filters unavailable (%v) - orders will not be rounded\n", err)
+			}
+			c.lastErr = err
+			c.loadedAt = time.Now() // Don't retry on every order
+		} else {
+			c.store(info)
+		}
+	}
+
+	return c.filters[symbol]
+}
+
+// sizingLimitsFor returns the sizing limits with the symbol's exchange minimum notional
+func sizingLimitsFor(symbol string) SizingLimits {
+	limits := DefaultSizingLimits()
+	if f := EXCHANGE_FILTERS.Get(symbol); f != nil && f.MinNotional > 0 {
+		limits.MinNotional = f.MinNotional
+	}
+	return limits
+}
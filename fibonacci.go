@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ==================== FIBONACCI LEVELS ====================
+
+// Fibonacci ratios
+var (
+	FIB_RETRACEMENTS = []float64{0.382, 0.5, 0.618, 0.786}
+	FIB_EXTENSIONS   = []float64{1.272, 1.618}
+)
+
+// FibSwing is the move Fibonacci levels are measured on
+type FibSwing struct {
+	Start     PivotPoint // Where the move began
+	End       PivotPoint // Where the move ended
+	Direction string     // "up" (low -> high) or "down" (high -> low)
+}
+
+// Range returns the absolute price distance of the swing
+func (s FibSwing) Range() float64 {
+	return math.Abs(s.End.Price - s.Start.Price)
+}
+
+// FibLevel is a single retracement or extension price
+type FibLevel struct {
+	Ratio float64
+	Price float64
+	Kind  string // "retracement" or "extension"
+}
+
+// Method returns the take-profit method label for this level
+func (l FibLevel) Method() string {
+	return fmt.Sprintf("%s_%s_%g", TP_METHOD_FIB, l.Kind, l.Ratio)
+}
+
+// findLatestSwing returns the most recent pivot-to-pivot move whose size is at
+// least minRange. Highs and lows are walked newest first, pairing each pivot
+// with the latest opposite pivot before it.
+func findLatestSwing(highs, lows []PivotPoint, minRange float64) *FibSwing {
+	// Merge pivots newest first
+	pivots := make([]PivotPoint, 0, len(highs)+len(lows))
+	pivots = append(pivots, highs...)
+	pivots = append(pivots, lows...)
+	for i := 0; i < len(pivots)-1; i++ {
+		for j := i + 1; j < len(pivots); j++ {
+			if pivots[j].Index > pivots[i].Index {
+				pivots[i], pivots[j] = pivots[j], pivots[i]
+			}
+		}
+	}
+
+	for i, end := range pivots {
+		for _, start := range pivots[i+1:] {
+			if start.IsHigh == end.IsHigh {
+				continue
+			}
+			swing := FibSwing{Start: start, End: end, Direction: "up"}
+			if !end.IsHigh {
+				swing.Direction = "down"
+			}
+			if swing.Range() >= minRange {
+				return &swing
+			}
+			break // Only the latest opposite pivot counts for this end
+		}
+	}
+
+	return nil
+}
+
+// calcFibonacciLevels projects retracements back into the swing and
+// extensions beyond its end (in the swing's direction)
+func calcFibonacciLevels(swing *FibSwing) []FibLevel {
+	if swing == nil {
+		return nil
+	}
+
+	start := swing.Start.Price
+	move := swing.End.Price - start
+	var levels []FibLevel
+
+	for _, r := range FIB_RETRACEMENTS {
+		levels = append(levels, FibLevel{Ratio: r, Price: swing.End.Price - r*move, Kind: "retracement"})
+	}
+	for _, r := range FIB_EXTENSIONS {
+		levels = append(levels, FibLevel{Ratio: r, Price: start + r*move, Kind: "extension"})
+	}
+
+	return levels
+}
+
+// IdentifyFibonacci measures the latest significant swing and stores its levels
+func (e *TradingEngine) IdentifyFibonacci() {
+	e.FibSwing = nil
+	e.FibLevels = nil
+	if len(e.Candles) == 0 {
+		return
+	}
+
+	atr := calcATR(e.Candles, e.SRConfig.ATRLength)
+	highs := findPivotHighs(e.Candles, atr, e.SRConfig.LookLeft, e.SRConfig.LookRight,
+		e.SRConfig.ATRMultiplier, e.SRConfig.MaxZonePercent)
+	lows := findPivotLows(e.Candles, atr, e.SRConfig.LookLeft, e.SRConfig.LookRight,
+		e.SRConfig.ATRMultiplier, e.SRConfig.MaxZonePercent)
+
+	minRange := 0.0
+	if last := atr[len(atr)-1]; last > 0 {
+		minRange = last * FIB_MIN_SWING_ATR
+	}
+
+	e.FibSwing = findLatestSwing(highs, lows, minRange)
+	e.FibLevels = calcFibonacciLevels(e.FibSwing)
+
+	if e.FibSwing != nil && SHOW_SR_ZONES {
+		fmt.Printf("   📐 Fibonacci swing %s: $%.2f → $%.2f (%s → %s)\n", e.FibSwing.Direction,
+			e.FibSwing.Start.Price, e.FibSwing.End.Price,
+			e.FibSwing.Start.Time.Format("01-02 15:04"), e.FibSwing.End.Time.Format("01-02 15:04"))
+		for _, level := range e.FibLevels {
+			fmt.Printf("      %-11s %.3f: $%.2f\n", level.Kind, level.Ratio, level.Price)
+		}
+	}
+}
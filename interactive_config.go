@@ -48,6 +48,7 @@ func PrintBotConfig(symbol, interval string, balance float64, mode string) {
 	fmt.Printf("   ATR Length:        %d\n", ATR_LENGTH)
 	fmt.Printf("   Min Divergences:   %d\n", MIN_DIVERGENCES_FOR_SIGNAL)
 	fmt.Printf("   Swing Lookback:    %d\n", SWING_LOOKBACK)
+	fmt.Printf("   Bar Type:          %s\n", BAR_CONFIG.Type)
 
 	fmt.Println("\n🎯 S/R ZONE SETTINGS:")
 	fmt.Printf("   Pivot Left:        %d\n", PIVOT_LEFT_LOOKBACK)
@@ -61,6 +62,9 @@ func PrintBotConfig(symbol, interval string, balance float64, mode string) {
 	fmt.Printf("   Max Risk:          %.1f%%\n", MAX_RISK_PERCENT)
 	fmt.Printf("   Stop Loss:         %.1f%%\n", STOP_LOSS_PERCENT)
 	fmt.Printf("   Take Profit:       %.1f%%\n", TAKE_PROFIT_PERCENT)
+	fmt.Printf("   Daily Loss Limit:  %.1f%%\n", MAX_DAILY_LOSS_PERCENT)
+	fmt.Printf("   Max Drawdown:      %.1f%%\n", MAX_DRAWDOWN_PERCENT)
+	fmt.Printf("   Max Loss Streak:   %d (on breach: %s)\n", MAX_CONSECUTIVE_LOSSES, RISK_BREACH_ACTION)
 
 	fmt.Println("\n🌍 TIMEZONE:")
 	fmt.Printf("   Current Time (IST): %s\n", getIST().Format("2006-01-02 15:04:05"))
@@ -87,6 +91,25 @@ func PrintMultiSymbolConfig(symbols []string, interval string, balance float64,
 	fmt.Printf("⏰ Interval:          %s\n", interval)
 	fmt.Printf("💰 Starting Balance:  $%.2f\n", balance)
 	fmt.Printf("🎯 Max Positions:     %d\n", maxPositions)
+	fmt.Printf("🏦 Broker:            %s\n", BROKER_MODE)
+	fmt.Printf("📌 Entry Orders:      %s\n", ENTRY_ORDER_TYPE)
+	fmt.Printf("📏 Trade Profile:     %s\n", TRADE_MANAGER_PROFILE)
+	fmt.Printf("⏳ Time Stops:        %s\n", describeTimeStops())
+	if DEPTH_SOURCE == nil {
+		fmt.Printf("💧 Depth Fills:       off\n")
+	} else if DEPTH_SOURCE.SnapshotDir != "" {
+		fmt.Printf("💧 Depth Fills:       recorded (%s, max slippage %.2f%%)\n", DEPTH_SOURCE.SnapshotDir, MAX_DEPTH_SLIPPAGE_PERCENT)
+	} else {
+		fmt.Printf("💧 Depth Fills:       live (%d levels, max slippage %.2f%%)\n", DEPTH_LEVELS, MAX_DEPTH_SLIPPAGE_PERCENT)
+	}
+	fmt.Printf("🏅 Rank Signals By:   %s\n", RANK_SIGNALS_BY)
+	fmt.Printf("➕ Scale-In:          %v (+%.0f%% on zone retest, max %d)\n",
+		SCALE_IN_ENABLED, SCALE_IN_ADD_PERCENT, SCALE_IN_MAX_ADDS)
+	fmt.Printf("🔄 Replace Weak:      %v (margin %.0f)\n", REPLACE_WEAK_POSITIONS, REPLACE_MIN_SCORE_MARGIN)
+	fmt.Printf("🧊 Cooldown:          %d candles after win, %d after loss\n",
+		COOLDOWN_CANDLES_AFTER_WIN, COOLDOWN_CANDLES_AFTER_LOSS)
+	fmt.Printf("🔗 Correlation Caps:  %d per cluster (ρ >= %.2f), cluster %.0f%%, net %.0f%% of equity\n",
+		MAX_CLUSTER_POSITIONS, CORRELATION_CLUSTER_THRESHOLD, MAX_CLUSTER_NOTIONAL_PERCENT, MAX_NET_EXPOSURE_PERCENT)
 
 	fmt.Println("\n🌐 MARKET CONFIGURATION:")
 	fmt.Printf("   Market Type:       %s\n", marketType)
@@ -123,6 +146,7 @@ func PrintMultiSymbolConfig(symbols []string, interval string, balance float64,
 	fmt.Printf("   RSI Period:        %d\n", RSI_PERIOD)
 	fmt.Printf("   ATR Length:        %d\n", ATR_LENGTH)
 	fmt.Printf("   Min Divergences:   %d\n", MIN_DIVERGENCES_FOR_SIGNAL)
+	fmt.Printf("   Bar Type:          %s\n", BAR_CONFIG.Type)
 
 	fmt.Println("\n🎯 S/R ZONE SETTINGS:")
 	fmt.Printf("   Pivot Left:        %d\n", PIVOT_LEFT_LOOKBACK)
@@ -132,6 +156,7 @@ func PrintMultiSymbolConfig(symbols []string, interval string, balance float64,
 	fmt.Println("\n💵 RISK MANAGEMENT:")
 	fmt.Printf("   Risk/Reward:       %.1f:1\n", RISK_REWARD_RATIO)
 	fmt.Printf("   Max Risk:          %.1f%% per trade\n", MAX_RISK_PERCENT)
+	fmt.Printf("   Position Sizing:   %s (max %.1fx leverage)\n", SIZING_MODE, MAX_LEVERAGE)
 	fmt.Printf("   Stop Loss:         %.1f%%\n", STOP_LOSS_PERCENT)
 	fmt.Printf("   Take Profit:       %.1f%%\n", TAKE_PROFIT_PERCENT)
 
@@ -142,6 +167,24 @@ func PrintMultiSymbolConfig(symbols []string, interval string, balance float64,
 	fmt.Println("════════════════════════════════════════════════════════════")
 }
 
+// describeTimeStops summarizes the enabled time stop rules
+func describeTimeStops() string {
+	var rules []string
+	if MAX_HOLD_CANDLES > 0 {
+		rules = append(rules, fmt.Sprintf("max %d candles", MAX_HOLD_CANDLES))
+	}
+	if MIN_PROFIT_CANDLES > 0 {
+		rules = append(rules, fmt.Sprintf("+%.2f%% by %d candles", MIN_PROFIT_PERCENT, MIN_PROFIT_CANDLES))
+	}
+	for _, window := range FLAT_WINDOWS {
+		rules = append(rules, window.Name)
+	}
+	if len(rules) == 0 {
+		return "off"
+	}
+	return strings.Join(rules, ", ")
+}
+
 // StartInteractiveMode listens for keyboard commands during runtime
 // statusCallback is optional - if provided, it will be called when 's' is pressed
 func StartInteractiveMode(configCallback func(), statusCallback ...func()) {
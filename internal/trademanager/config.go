@@ -1,23 +1,110 @@
 package trademanager
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Config holds the 3-Tier trade management configuration
 type Config struct {
 	// Tier 1: Breakeven Lock
 	Tier1BreakevenThreshold float64 // % profit to trigger breakeven (default: 0.5)
 
 	// Tier 2: Partial Exit
-	Tier2PartialExitThreshold float64 // % profit to trigger partial exit (default: 1.5)
-	Tier2PartialExitPercent   float64 // % of position to close (default: 50)
+	Tier2PartialExitThreshold float64    // % profit to trigger partial exit (default: 1.5)
+	Tier2PartialExitPercent   float64    // % of position to close (default: 50)
+	Tier2Ladder               []ExitStep // Multi-step exits, fired in order (empty = the single step above)
 
 	// Tier 3: Time-Based Lock
 	Tier3TimeThreshold      int     // Seconds in profit before tightening (default: 300 = 5 min)
 	Tier3MinProfitThreshold float64 // Minimum profit % to activate time-based (default: 1.0)
 	Tier3ProfitLockPercent  float64 // % of max profit to lock (default: 60)
 
+	// Tier 3: Trailing Stop Mode (see trailing.go)
+	TrailMode          string  // TRAIL_PROFIT_LOCK (time-based lock above), TRAIL_ATR, TRAIL_SWING or TRAIL_PSAR
+	TrailATRMultiple   float64 // Chandelier: ATR multiples from the best price since entry
+	TrailSwingStrength int     // Swing: bars on each side that confirm a pivot
+	TrailPSARStep      float64 // Parabolic SAR acceleration step
+	TrailPSARMax       float64 // Parabolic SAR maximum acceleration
+
+	// Time Stops (see timestops.go; zero values disable each rule)
+	CandleDuration   time.Duration // Candle length the candle counts are measured in
+	MaxHoldCandles   int           // Close after this many candles
+	MinProfitCandles int           // Close if not MinProfitPercent in profit after this many candles
+	MinProfitPercent float64       // Profit % required by MinProfitCandles
+	FlatWindows      []TimeWindow  // Windows to be flat for
+	FlatLeadMinutes  int           // Close this long before a flat window starts
+
 	// General settings
 	Enabled bool // Master switch to enable/disable 3-Tier system
 }
 
+// ExitStep is one rung of the Tier 2 partial exit ladder
+type ExitStep struct {
+	Trigger     float64 // Profit % that fires the step, or an R multiple when InR
+	InR         bool    // Trigger is a multiple of the initial risk (entry to initial stop)
+	ExitPercent float64 // % of the original position to close
+}
+
+// String formats the step as accepted by ParseExitLadder
+func (s ExitStep) String() string {
+	trigger := strconv.FormatFloat(s.Trigger, 'f', -1, 64)
+	if s.InR {
+		trigger += "R"
+	}
+	return trigger + ":" + strconv.FormatFloat(s.ExitPercent, 'f', -1, 64)
+}
+
+// ParseExitLadder parses "trigger:exit%" steps separated by commas, where a
+// trigger is a profit % or an R multiple with an R suffix (e.g., "0.5:25,1R:25,2R:25").
+// Steps must leave part of the position for the stop or target.
+func ParseExitLadder(spec string) ([]ExitStep, error) {
+	var steps []ExitStep
+	total := 0.0
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		trigger, exit, found := strings.Cut(part, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid exit step %q (use trigger:exit%%)", part)
+		}
+
+		step := ExitStep{}
+		trigger = strings.TrimSpace(trigger)
+		if strings.HasSuffix(strings.ToUpper(trigger), "R") {
+			step.InR = true
+			trigger = trigger[:len(trigger)-1]
+		}
+		var err error
+		if step.Trigger, err = strconv.ParseFloat(trigger, 64); err != nil || step.Trigger <= 0 {
+			return nil, fmt.Errorf("invalid trigger in exit step %q", part)
+		}
+		if step.ExitPercent, err = strconv.ParseFloat(strings.TrimSpace(exit), 64); err != nil || step.ExitPercent <= 0 {
+			return nil, fmt.Errorf("invalid exit %% in exit step %q", part)
+		}
+
+		total += step.ExitPercent
+		steps = append(steps, step)
+	}
+
+	if total >= 100 {
+		return nil, fmt.Errorf("exit ladder closes %.0f%% - leave part of the position for the stop/target", total)
+	}
+	return steps, nil
+}
+
+// ladder returns the exit steps (the single Tier 2 step when no ladder is set)
+func (c *Config) ladder() []ExitStep {
+	if len(c.Tier2Ladder) > 0 {
+		return c.Tier2Ladder
+	}
+	return []ExitStep{{Trigger: c.Tier2PartialExitThreshold, ExitPercent: c.Tier2PartialExitPercent}}
+}
+
 // DefaultConfig returns the recommended default configuration
 // OPTIMIZED FOR 0.4% SL / 0.8% TP SCALPING STRATEGY (1m timeframe)
 func DefaultConfig() *Config {
@@ -28,6 +115,11 @@ func DefaultConfig() *Config {
 		Tier3TimeThreshold:        180,  // 3 minutes (appropriate for 1m scalping)
 		Tier3MinProfitThreshold:   0.4,  // Must be at least +0.4% profit (matches SL)
 		Tier3ProfitLockPercent:    60.0, // Lock 60% of max profit reached
+		TrailMode:                 TRAIL_PROFIT_LOCK,
+		TrailATRMultiple:          3.0,  // Classic Chandelier exit
+		TrailSwingStrength:        2,    // 2 bars each side
+		TrailPSARStep:             0.02, // Wilder's defaults
+		TrailPSARMax:              0.2,
 		Enabled:                   true,
 	}
 }
@@ -41,6 +133,11 @@ func AggressiveConfig() *Config {
 		Tier3TimeThreshold:        180,  // 3 minutes
 		Tier3MinProfitThreshold:   0.7,
 		Tier3ProfitLockPercent:    70.0, // Lock more profit
+		TrailMode:                 TRAIL_PROFIT_LOCK,
+		TrailATRMultiple:          2.0, // Tighter trail
+		TrailSwingStrength:        1,
+		TrailPSARStep:             0.03,
+		TrailPSARMax:              0.25,
 		Enabled:                   true,
 	}
 }
@@ -54,6 +151,61 @@ func ConservativeConfig() *Config {
 		Tier3TimeThreshold:        420,  // 7 minutes
 		Tier3MinProfitThreshold:   1.5,
 		Tier3ProfitLockPercent:    50.0, // Lock less (more room to run)
+		TrailMode:                 TRAIL_PROFIT_LOCK,
+		TrailATRMultiple:          4.0, // Wider trail
+		TrailSwingStrength:        3,
+		TrailPSARStep:             0.015,
+		TrailPSARMax:              0.15,
 		Enabled:                   true,
 	}
 }
+
+// ChandelierConfig trails the stop an ATR multiple from the best price since entry
+func ChandelierConfig() *Config {
+	config := DefaultConfig()
+	config.TrailMode = TRAIL_ATR
+	return config
+}
+
+// SwingTrailConfig trails the stop to the last confirmed swing pivot
+func SwingTrailConfig() *Config {
+	config := DefaultConfig()
+	config.TrailMode = TRAIL_SWING
+	return config
+}
+
+// ParabolicSARConfig trails the stop along the Parabolic SAR
+func ParabolicSARConfig() *Config {
+	config := DefaultConfig()
+	config.TrailMode = TRAIL_PSAR
+	return config
+}
+
+// Config profile names (see ConfigForProfile)
+const (
+	PROFILE_DEFAULT      = "default"
+	PROFILE_AGGRESSIVE   = "aggressive"
+	PROFILE_CONSERVATIVE = "conservative"
+	PROFILE_CHANDELIER   = "chandelier"
+	PROFILE_SWING        = "swing"
+	PROFILE_PSAR         = "psar"
+)
+
+// ConfigForProfile returns the named configuration profile
+func ConfigForProfile(name string) (*Config, error) {
+	switch strings.ToLower(name) {
+	case PROFILE_DEFAULT, "":
+		return DefaultConfig(), nil
+	case PROFILE_AGGRESSIVE:
+		return AggressiveConfig(), nil
+	case PROFILE_CONSERVATIVE:
+		return ConservativeConfig(), nil
+	case PROFILE_CHANDELIER:
+		return ChandelierConfig(), nil
+	case PROFILE_SWING:
+		return SwingTrailConfig(), nil
+	case PROFILE_PSAR:
+		return ParabolicSARConfig(), nil
+	}
+	return nil, fmt.Errorf("unknown trade manager profile %q (use default, aggressive, conservative, chandelier, swing or psar)", name)
+}
@@ -3,6 +3,7 @@ package trademanager
 import (
 	"fmt"
 	"sync"
+	"time"
 )
 
 // Manager is the main trade management system that coordinates 3-Tier logic
@@ -15,12 +16,16 @@ type Manager struct {
 	partialExitCb   PartialExitCallback   // Callback for partial exits
 	stopUpdateCb    StopUpdateCallback    // Callback for stop loss updates
 	positionCloseCb PositionCloseCallback // Callback for position close
+	priceRounder    PriceRounder          // Rounds stop prices to the exchange tick size
 }
 
 // Callbacks for integration with existing trading engine
-type PartialExitCallback func(symbol string, exitPercent, currentPrice float64) (exitedProfit float64, err error)
+type PartialExitCallback func(symbol string, exitPercent, currentPrice float64) (quantity, fillPrice float64, err error)
 type StopUpdateCallback func(symbol string, newStopLoss float64) error
-type PositionCloseCallback func(symbol string, reason string) error
+type PositionCloseCallback func(symbol string, reason string, currentPrice float64) error
+
+// PriceRounder rounds a price to what the exchange accepts for the symbol
+type PriceRounder func(symbol string, price float64) float64
 
 // NewManager creates a new trade manager
 func NewManager(config *Config, verbose bool) *Manager {
@@ -47,6 +52,19 @@ func (m *Manager) SetCallbacks(
 	m.positionCloseCb = positionClose
 }
 
+// SetPriceRounder configures rounding for stop moves (nil = unrounded)
+func (m *Manager) SetPriceRounder(rounder PriceRounder) {
+	m.priceRounder = rounder
+}
+
+// roundPrice applies the price rounder if one is set
+func (m *Manager) roundPrice(symbol string, price float64) float64 {
+	if m.priceRounder == nil {
+		return price
+	}
+	return m.priceRounder(symbol, price)
+}
+
 // AddPosition adds a new position to be managed
 func (m *Manager) AddPosition(id int, symbol, side string, entryPrice, stopLoss, takeProfit, size float64) {
 	m.mutex.Lock()
@@ -67,6 +85,28 @@ func (m *Manager) AddPositionWithAdaptiveConfig(id int, symbol, side string, ent
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	adaptedConfig, slDistancePct := m.adaptiveConfig(side, entryPrice, stopLoss)
+
+	// Create position with adapted config
+	pos := NewManagedPosition(id, symbol, side, entryPrice, stopLoss, takeProfit, size)
+	pos.Tiers = adaptedConfig
+	m.positions[symbol] = pos
+
+	if m.verbose {
+		fmt.Printf("\n✅ Trade Manager: Added position %s (ID: %d) [ADAPTIVE MODE]\n", symbol, id)
+		fmt.Printf("   Entry: $%.2f | SL: $%.2f (%.2f%%) | TP: $%.2f\n", 
+			entryPrice, stopLoss, slDistancePct, takeProfit)
+		fmt.Printf("   🔧 Adapted Tiers: %.2f%% BE | %.2f%% Partial | %ds Trailing\n",
+			adaptedConfig.Tier1BreakevenThreshold,
+			adaptedConfig.Tier2PartialExitThreshold,
+			adaptedConfig.Tier3TimeThreshold)
+	}
+}
+
+// adaptiveConfig scales the tier thresholds to the stop distance from entry.
+// Strategy: Tier 1 at 40% of SL distance, Tier 2 at 70% of SL distance.
+// Returns the config and the SL distance as a percentage.
+func (m *Manager) adaptiveConfig(side string, entryPrice, stopLoss float64) (*Config, float64) {
 	// Calculate actual SL distance as percentage
 	var slDistancePct float64
 	if side == "SHORT" {
@@ -75,49 +115,94 @@ func (m *Manager) AddPositionWithAdaptiveConfig(id int, symbol, side string, ent
 		slDistancePct = ((entryPrice - stopLoss) / entryPrice) * 100
 	}
 
-	// Adapt tier thresholds to the actual SL distance
-	// Strategy: Tier 1 at 40% of SL distance, Tier 2 at 70% of SL distance
-	adaptedConfig := &Config{
-		Tier1BreakevenThreshold:   slDistancePct * 0.4,  // 40% to SL
-		Tier2PartialExitThreshold: slDistancePct * 0.7,  // 70% to SL (before SL hits)
+	return &Config{
+		Tier1BreakevenThreshold:   slDistancePct * 0.4, // 40% to SL
+		Tier2PartialExitThreshold: slDistancePct * 0.7, // 70% to SL (before SL hits)
 		Tier2PartialExitPercent:   m.config.Tier2PartialExitPercent,
+		Tier2Ladder:               m.config.Tier2Ladder,
 		Tier3TimeThreshold:        m.config.Tier3TimeThreshold,
-		Tier3MinProfitThreshold:   slDistancePct * 0.3,  // 30% to SL
+		Tier3MinProfitThreshold:   slDistancePct * 0.3, // 30% to SL
 		Tier3ProfitLockPercent:    m.config.Tier3ProfitLockPercent,
+		TrailMode:                 m.config.TrailMode,
+		TrailATRMultiple:          m.config.TrailATRMultiple,
+		TrailSwingStrength:        m.config.TrailSwingStrength,
+		TrailPSARStep:             m.config.TrailPSARStep,
+		TrailPSARMax:              m.config.TrailPSARMax,
+		CandleDuration:            m.config.CandleDuration,
+		MaxHoldCandles:            m.config.MaxHoldCandles,
+		MinProfitCandles:          m.config.MinProfitCandles,
+		MinProfitPercent:          m.config.MinProfitPercent,
+		FlatWindows:               m.config.FlatWindows,
+		FlatLeadMinutes:           m.config.FlatLeadMinutes,
 		Enabled:                   true,
+	}, slDistancePct
+}
+
+// ScaleIn adds size at price to a managed position. The entry is re-averaged
+// and adaptive tier thresholds are recomputed from the new entry. Adds are
+// refused once a Tier 2 partial exit has been taken; a breakeven lock that
+// the new entry leaves on the losing side is re-armed.
+func (m *Manager) ScaleIn(symbol string, price, size float64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	pos, exists := m.positions[symbol]
+	if !exists {
+		return fmt.Errorf("no active position for %s", symbol)
+	}
+	if len(pos.Exits) > 0 {
+		return fmt.Errorf("%s already took a partial exit", symbol)
 	}
 
-	// Create position with adapted config
-	pos := NewManagedPosition(id, symbol, side, entryPrice, stopLoss, takeProfit, size)
-	m.positions[symbol] = pos
+	oldEntry := pos.EntryPrice
+	pos.AddToPosition(price, size)
+	if pos.Tiers != nil {
+		pos.Tiers, _ = m.adaptiveConfig(pos.Side, pos.EntryPrice, pos.StopLoss)
+	}
 
-	// Update tier manager with adapted config for this position
-	m.tierManager.config = adaptedConfig
+	// The breakeven stop sits at the old entry; re-arm Tier 1 if that's now a loss
+	if pos.Tier1Activated && ((pos.Side == "SHORT" && pos.StopLoss > pos.EntryPrice) ||
+		(pos.Side == "LONG" && pos.StopLoss < pos.EntryPrice)) {
+		pos.Tier1Activated = false
+	}
 
 	if m.verbose {
-		fmt.Printf("\n✅ Trade Manager: Added position %s (ID: %d) [ADAPTIVE MODE]\n", symbol, id)
-		fmt.Printf("   Entry: $%.2f | SL: $%.2f (%.2f%%) | TP: $%.2f\n", 
-			entryPrice, stopLoss, slDistancePct, takeProfit)
-		fmt.Printf("   🔧 Adapted Tiers: %.2f%% BE | %.2f%% Partial | %ds Trailing\n",
-			adaptedConfig.Tier1BreakevenThreshold,
-			adaptedConfig.Tier2PartialExitThreshold,
-			adaptedConfig.Tier3TimeThreshold)
+		tiers := pos.Tiers
+		if tiers == nil {
+			tiers = m.config
+		}
+		fmt.Printf("\n➕ Trade Manager: Scaled into %s (add #%d, $%.2f @ $%.4f)\n", symbol, pos.ScaleIns, size, price)
+		fmt.Printf("   Entry: $%.4f → $%.4f | Size: $%.2f\n", oldEntry, pos.EntryPrice, pos.RemainingSize)
+		fmt.Printf("   🔧 Tiers: %.2f%% BE | %.2f%% Partial | %ds Trailing\n",
+			tiers.Tier1BreakevenThreshold, tiers.Tier2PartialExitThreshold, tiers.Tier3TimeThreshold)
 	}
+	return nil
 }
 
 // UpdatePrice updates the price for a position and evaluates 3-Tier rules
 func (m *Manager) UpdatePrice(symbol string, currentPrice float64) error {
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
 
 	pos, exists := m.positions[symbol]
 	if !exists {
+		m.mutex.Unlock()
 		return fmt.Errorf("no active position for %s", symbol)
 	}
 
 	// Update position price and metrics
 	pos.UpdatePrice(currentPrice)
 
+	// Time stops close the whole position through the close callback, which
+	// runs outside the lock so the engine can clean up through the manager
+	if m.positionCloseCb != nil {
+		if action := m.tierManager.checkTimeStop(pos, time.Now()); action != nil {
+			delete(m.positions, symbol)
+			m.mutex.Unlock()
+			return m.executeClose(pos, action)
+		}
+	}
+	defer m.mutex.Unlock()
+
 	// Evaluate 3-Tier rules
 	action := m.tierManager.EvaluatePosition(pos)
 
@@ -143,8 +228,29 @@ func (m *Manager) executeAction(pos *ManagedPosition, action *TierAction) error
 	}
 }
 
+// executeClose hands a time stop to the close callback and restores the
+// position if the close fails (called without the lock)
+func (m *Manager) executeClose(pos *ManagedPosition, action *TierAction) error {
+	if m.verbose {
+		fmt.Printf("\n%s\n", action.Reason)
+		fmt.Printf("   Closing %s @ $%.4f\n", pos.Symbol, pos.CurrentPrice)
+	}
+
+	if err := m.positionCloseCb(pos.Symbol, CLOSE_TIME_STOP, pos.CurrentPrice); err != nil {
+		m.mutex.Lock()
+		if _, exists := m.positions[pos.Symbol]; !exists {
+			m.positions[pos.Symbol] = pos
+		}
+		m.mutex.Unlock()
+		return fmt.Errorf("failed to close position: %w", err)
+	}
+	return nil
+}
+
 // executeMoveStop updates the stop loss
 func (m *Manager) executeMoveStop(pos *ManagedPosition, action *TierAction) error {
+	action.NewStopLoss = m.roundPrice(pos.Symbol, action.NewStopLoss)
+
 	// Update internal state
 	oldStopLoss := pos.StopLoss
 	pos.StopLoss = action.NewStopLoss
@@ -184,15 +290,17 @@ func (m *Manager) executePartialExit(pos *ManagedPosition, action *TierAction) e
 	}
 
 	// Execute partial exit via callback
-	exitedProfit, err := m.partialExitCb(pos.Symbol, action.ExitPercent, pos.CurrentPrice)
+	quantity, fillPrice, err := m.partialExitCb(pos.Symbol, action.ExitPercent, pos.CurrentPrice)
 	if err != nil {
 		return fmt.Errorf("failed to execute partial exit: %w", err)
 	}
 
-	// Update position state
-	pos.ApplyPartialExit(action.ExitPercent, pos.CurrentPrice)
+	// Update position state with what filled (Tier 2 completes with the last ladder step)
+	exitedProfit := pos.ApplyPartialExit(quantity*pos.EntryPrice, fillPrice)
+	pos.Tier2Activated = len(pos.Exits) >= len(m.tierManager.configFor(pos).ladder())
 
 	// Also update stop to breakeven
+	action.NewStopLoss = m.roundPrice(pos.Symbol, action.NewStopLoss)
 	oldStopLoss := pos.StopLoss
 	pos.StopLoss = action.NewStopLoss
 
@@ -205,8 +313,8 @@ func (m *Manager) executePartialExit(pos *ManagedPosition, action *TierAction) e
 	if m.verbose {
 		fmt.Printf("\n%s\n", action.Reason)
 		fmt.Printf("   Closed %.0f%% (${%.2f}) | Profit: $%.4f\n",
-			action.ExitPercent,
-			pos.Tier2ExitedSize,
+			pos.Exits[len(pos.Exits)-1].Percent,
+			pos.Exits[len(pos.Exits)-1].Size,
 			exitedProfit)
 		fmt.Printf("   Remaining: $%.2f | Stop: $%.4f → $%.4f\n",
 			pos.RemainingSize,
@@ -1,6 +1,9 @@
 package trademanager
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // ManagedPosition wraps a position with 3-Tier state tracking
 type ManagedPosition struct {
@@ -11,9 +14,12 @@ type ManagedPosition struct {
 	EntryPrice   float64
 	EntryTime    time.Time
 	StopLoss     float64
+	InitialStop  float64 // Stop at entry, the risk unit for R-multiple exits
 	TakeProfit   float64
 	Size         float64
-	OriginalSize float64 // Track original size for partial exits
+	OriginalSize float64 // Track original size for partial exits (grows with scale-ins)
+	Tiers        *Config // Per-position thresholds (nil = manager config)
+	ScaleIns     int     // Adds since entry
 
 	// Current state
 	CurrentPrice  float64
@@ -22,17 +28,20 @@ type ManagedPosition struct {
 	MaxProfit     float64
 	MaxProfitPct  float64
 	RemainingSize float64
+	Bars          []Bar   // Recent candles, oldest first (see Manager.UpdateMarketData)
+	ATR           float64 // Current ATR for the Chandelier trail
 
 	// Tier state tracking
 	Tier1Activated       bool      // Breakeven lock activated
 	Tier1ActivationTime  time.Time // When breakeven was activated
 	Tier1ActivationPrice float64   // Price when breakeven activated
 
-	Tier2Activated       bool      // Partial exit completed
-	Tier2ActivationTime  time.Time // When partial exit happened
-	Tier2ActivationPrice float64   // Price when partial exit happened
-	Tier2ExitedSize      float64   // Amount exited in Tier 2
-	Tier2ExitedProfit    float64   // Profit from Tier 2 exit
+	Tier2Activated       bool          // All partial exits completed
+	Tier2ActivationTime  time.Time     // When the last partial exit happened
+	Tier2ActivationPrice float64       // Price of the last partial exit
+	Tier2ExitedSize      float64       // Total amount exited in Tier 2
+	Tier2ExitedProfit    float64       // Total profit from Tier 2 exits
+	Exits                []PartialExit // Ledger of partial exits, oldest first
 
 	Tier3Activated       bool      // Time-based lock activated
 	Tier3ActivationTime  time.Time // When time lock activated
@@ -44,6 +53,15 @@ type ManagedPosition struct {
 	TimeInProfit        float64   // Seconds spent in profit
 }
 
+// PartialExit is one executed step of the exit ladder
+type PartialExit struct {
+	Time    time.Time
+	Price   float64
+	Percent float64 // % of the remaining position closed
+	Size    float64 // Notional closed (at entry)
+	Profit  float64
+}
+
 // NewManagedPosition creates a new managed position from basic parameters
 func NewManagedPosition(id int, symbol, side string, entryPrice, stopLoss, takeProfit, size float64) *ManagedPosition {
 	return &ManagedPosition{
@@ -53,6 +71,7 @@ func NewManagedPosition(id int, symbol, side string, entryPrice, stopLoss, takeP
 		EntryPrice:    entryPrice,
 		EntryTime:     time.Now(),
 		StopLoss:      stopLoss,
+		InitialStop:   stopLoss,
 		TakeProfit:    takeProfit,
 		Size:          size,
 		OriginalSize:  size,
@@ -133,9 +152,26 @@ func (p *ManagedPosition) GetTimeInProfitDuration() time.Duration {
 	return time.Since(p.FirstProfitableTime)
 }
 
-// ApplyPartialExit reduces position size and records the exit
-func (p *ManagedPosition) ApplyPartialExit(exitPercent, exitPrice float64) float64 {
-	exitSize := p.RemainingSize * (exitPercent / 100.0)
+// InitialRiskPct returns the distance from entry to the initial stop as a %
+// of entry (1R)
+func (p *ManagedPosition) InitialRiskPct() float64 {
+	if p.EntryPrice <= 0 {
+		return 0
+	}
+	if p.Side == "SHORT" {
+		return (p.InitialStop - p.EntryPrice) / p.EntryPrice * 100
+	}
+	return (p.EntryPrice - p.InitialStop) / p.EntryPrice * 100
+}
+
+// ApplyPartialExit reduces position size by exitSize (notional at entry, as
+// filled) and records the exit in the ledger
+func (p *ManagedPosition) ApplyPartialExit(exitSize, exitPrice float64) float64 {
+	exitSize = math.Min(exitSize, p.RemainingSize)
+	exitPercent := 0.0
+	if p.RemainingSize > 0 {
+		exitPercent = exitSize / p.RemainingSize * 100
+	}
 
 	// Calculate profit from this partial exit
 	var exitProfit float64
@@ -147,15 +183,32 @@ func (p *ManagedPosition) ApplyPartialExit(exitPercent, exitPrice float64) float
 
 	// Update position state
 	p.RemainingSize -= exitSize
-	p.Tier2ExitedSize = exitSize
-	p.Tier2ExitedProfit = exitProfit
-	p.Tier2Activated = true
+	p.Tier2ExitedSize += exitSize
+	p.Tier2ExitedProfit += exitProfit
 	p.Tier2ActivationTime = time.Now()
 	p.Tier2ActivationPrice = exitPrice
+	p.Exits = append(p.Exits, PartialExit{
+		Time:    p.Tier2ActivationTime,
+		Price:   exitPrice,
+		Percent: exitPercent,
+		Size:    exitSize,
+		Profit:  exitProfit,
+	})
 
 	return exitProfit
 }
 
+// AddToPosition adds size (notional at price) and re-averages the entry
+// over the quantity of both legs
+func (p *ManagedPosition) AddToPosition(price, size float64) {
+	quantity := p.RemainingSize/p.EntryPrice + size/price
+	p.EntryPrice = (p.RemainingSize + size) / quantity
+	p.Size += size
+	p.OriginalSize += size
+	p.RemainingSize += size
+	p.ScaleIns++
+}
+
 // GetTotalProfit returns combined profit from all exits
 func (p *ManagedPosition) GetTotalProfit() float64 {
 	currentProfit, _ := p.CalculateCurrentProfit()
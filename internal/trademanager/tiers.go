@@ -2,6 +2,7 @@ package trademanager
 
 import (
 	"fmt"
+	"math"
 )
 
 // TierManager handles the 3-Tier trade management logic
@@ -21,7 +22,7 @@ func NewTierManager(config *Config) *TierManager {
 
 // TierAction represents an action that should be taken
 type TierAction struct {
-	Type          string  // "MOVE_STOP", "PARTIAL_EXIT", "NONE"
+	Type          string  // "MOVE_STOP", "PARTIAL_EXIT", "CLOSE", "NONE"
 	NewStopLoss   float64 // New stop loss price (for MOVE_STOP)
 	ExitPercent   float64 // Percentage to exit (for PARTIAL_EXIT)
 	Reason        string  // Human-readable reason
@@ -50,6 +51,15 @@ func (tm *TierManager) EvaluatePosition(pos *ManagedPosition) *TierAction {
 		}
 	}
 
+	// Tier 3: ATR, swing and Parabolic SAR modes trail from entry instead of
+	// the time-based lock
+	if mode := tm.configFor(pos).TrailMode; mode != "" && mode != TRAIL_PROFIT_LOCK {
+		if action := tm.checkTrailingStop(pos); action != nil {
+			return action
+		}
+		return &TierAction{Type: "NONE"}
+	}
+
 	// Tier 3: Time-Based Lock (only if Tier 1 is active)
 	if pos.Tier1Activated && !pos.Tier3Activated {
 		if action := tm.checkTier3(pos); action != nil {
@@ -71,7 +81,7 @@ func (tm *TierManager) EvaluatePosition(pos *ManagedPosition) *TierAction {
 func (tm *TierManager) checkTier1(pos *ManagedPosition) *TierAction {
 	profitPct := pos.GetCurrentProfitPct()
 
-	if profitPct >= tm.config.Tier1BreakevenThreshold {
+	if profitPct >= tm.configFor(pos).Tier1BreakevenThreshold {
 		return &TierAction{
 			Type:          "MOVE_STOP",
 			NewStopLoss:   pos.EntryPrice,
@@ -83,21 +93,42 @@ func (tm *TierManager) checkTier1(pos *ManagedPosition) *TierAction {
 	return nil
 }
 
-// checkTier2 evaluates Tier 2: Partial Exit
+// checkTier2 evaluates Tier 2: the next step of the partial exit ladder
 func (tm *TierManager) checkTier2(pos *ManagedPosition) *TierAction {
+	ladder := tm.configFor(pos).ladder()
+	next := len(pos.Exits)
+	if next >= len(ladder) {
+		return nil
+	}
+	step := ladder[next]
+
+	threshold, label := step.Trigger, fmt.Sprintf("+%.2f%%", step.Trigger)
+	if step.InR {
+		threshold, label = step.Trigger*pos.InitialRiskPct(), fmt.Sprintf("%.2fR", step.Trigger)
+	}
+
 	profitPct := pos.GetCurrentProfitPct()
+	if threshold <= 0 || profitPct < threshold {
+		return nil
+	}
 
-	if profitPct >= tm.config.Tier2PartialExitThreshold {
-		return &TierAction{
-			Type:          "PARTIAL_EXIT",
-			ExitPercent:   tm.config.Tier2PartialExitPercent,
-			NewStopLoss:   pos.EntryPrice, // Keep at breakeven after partial exit
-			Reason:        fmt.Sprintf("💰 Tier 2: Partial Exit %.0f%% at +%.2f%%", tm.config.Tier2PartialExitPercent, profitPct),
-			TierActivated: 2,
-		}
+	// Steps are sized on the original position; the callback closes a share of what's left
+	exitPercent := math.Min(100, step.ExitPercent*pos.OriginalSize/pos.RemainingSize)
+
+	// Keep at breakeven after a partial exit, unless the stop is already tighter
+	newStopLoss := pos.EntryPrice
+	if (pos.Side == "SHORT" && pos.StopLoss < newStopLoss) || (pos.Side == "LONG" && pos.StopLoss > newStopLoss) {
+		newStopLoss = pos.StopLoss
 	}
 
-	return nil
+	return &TierAction{
+		Type:        "PARTIAL_EXIT",
+		ExitPercent: exitPercent,
+		NewStopLoss: newStopLoss,
+		Reason: fmt.Sprintf("💰 Tier 2: Partial Exit %d/%d - %.0f%% at %s (+%.2f%%)",
+			next+1, len(ladder), step.ExitPercent, label, profitPct),
+		TierActivated: 2,
+	}
 }
 
 // checkTier3 evaluates Tier 3: Time-Based Lock (initial activation)
@@ -106,8 +137,8 @@ func (tm *TierManager) checkTier3(pos *ManagedPosition) *TierAction {
 	timeInProfit := pos.TimeInProfit
 
 	// Check if conditions are met for Tier 3 activation
-	if profitPct >= tm.config.Tier3MinProfitThreshold &&
-		timeInProfit >= float64(tm.config.Tier3TimeThreshold) {
+	if profitPct >= tm.configFor(pos).Tier3MinProfitThreshold &&
+		timeInProfit >= float64(tm.configFor(pos).Tier3TimeThreshold) {
 
 		// Calculate lock price based on max profit reached
 		lockPrice := tm.calculateTier3LockPrice(pos)
@@ -117,7 +148,7 @@ func (tm *TierManager) checkTier3(pos *ManagedPosition) *TierAction {
 			NewStopLoss: lockPrice,
 			Reason: fmt.Sprintf("⏰ Tier 3: Time Lock (%.0fs in profit, locking %.0f%% of max %.2f%%)",
 				timeInProfit,
-				tm.config.Tier3ProfitLockPercent,
+				tm.configFor(pos).Tier3ProfitLockPercent,
 				pos.MaxProfitPct),
 			TierActivated: 3,
 		}
@@ -139,7 +170,7 @@ func (tm *TierManager) updateTier3(pos *ManagedPosition) *TierAction {
 			Type:        "MOVE_STOP",
 			NewStopLoss: newLockPrice,
 			Reason: fmt.Sprintf("⏰ Tier 3: Trail Update (locking %.0f%% of max %.2f%%)",
-				tm.config.Tier3ProfitLockPercent,
+				tm.configFor(pos).Tier3ProfitLockPercent,
 				pos.MaxProfitPct),
 			TierActivated: 3,
 		}
@@ -151,7 +182,7 @@ func (tm *TierManager) updateTier3(pos *ManagedPosition) *TierAction {
 // calculateTier3LockPrice calculates the stop loss price that locks in X% of max profit
 func (tm *TierManager) calculateTier3LockPrice(pos *ManagedPosition) float64 {
 	// We want to lock in X% of the max profit reached
-	lockPercent := tm.config.Tier3ProfitLockPercent / 100.0
+	lockPercent := tm.configFor(pos).Tier3ProfitLockPercent / 100.0
 
 	if pos.Side == "SHORT" {
 		// For SHORT: entry - (entry - lowest) * lockPercent
@@ -166,6 +197,14 @@ func (tm *TierManager) calculateTier3LockPrice(pos *ManagedPosition) float64 {
 	}
 }
 
+// configFor returns the position's own thresholds, or the shared config
+func (tm *TierManager) configFor(pos *ManagedPosition) *Config {
+	if pos.Tiers != nil {
+		return pos.Tiers
+	}
+	return tm.config
+}
+
 // GetConfig returns the current configuration
 func (tm *TierManager) GetConfig() *Config {
 	return tm.config
@@ -192,10 +231,15 @@ func (tm *TierManager) PrintStatus(pos *ManagedPosition) {
 	fmt.Println("\n🎯 Tier Status:")
 	fmt.Printf("  Tier 1 (Breakeven): %s\n", tm.getTierStatus(pos.Tier1Activated))
 	fmt.Printf("  Tier 2 (Partial):   %s", tm.getTierStatus(pos.Tier2Activated))
-	if pos.Tier2Activated {
-		fmt.Printf(" - Exited $%.2f (%.0f%%)", pos.Tier2ExitedProfit, (pos.Tier2ExitedSize/pos.OriginalSize)*100)
+	if len(pos.Exits) > 0 {
+		fmt.Printf(" - %d/%d steps, exited $%.2f (%.0f%%), profit $%.2f", len(pos.Exits), len(tm.configFor(pos).ladder()),
+			pos.Tier2ExitedSize, (pos.Tier2ExitedSize/pos.OriginalSize)*100, pos.Tier2ExitedProfit)
 	}
 	fmt.Println()
+	for i, exit := range pos.Exits {
+		fmt.Printf("     %d. %s @ $%.4f - closed %.0f%% ($%.2f), profit $%.4f\n",
+			i+1, exit.Time.Format("15:04:05"), exit.Price, exit.Percent, exit.Size, exit.Profit)
+	}
 	fmt.Printf("  Tier 3 (Time Lock): %s\n", tm.getTierStatus(pos.Tier3Activated))
 	fmt.Println("════════════════════════════════════════")
 }
@@ -0,0 +1,80 @@
+package trademanager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CLOSE_TIME_STOP is the reason passed to the PositionCloseCallback for time stops
+const CLOSE_TIME_STOP = "TIME_STOP"
+
+// TimeWindow is a recurring window to be flat for (the engine's session
+// windows satisfy it)
+type TimeWindow interface {
+	Contains(t time.Time) bool
+	String() string
+}
+
+// ParseProfitCheck parses a minimum profit check as PCT:CANDLES, e.g. 0.5:20
+// closes positions not at least +0.5% in profit after 20 candles ("" = off)
+func ParseProfitCheck(spec string) (percent float64, candles int, err error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return 0, 0, nil
+	}
+
+	pct, count, found := strings.Cut(spec, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("profit check %q: expected PCT:CANDLES", spec)
+	}
+	percent, err = strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(pct), "%"), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("profit check %q: invalid percent", spec)
+	}
+	candles, err = strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || candles <= 0 {
+		return 0, 0, fmt.Errorf("profit check %q: candles must be a positive integer", spec)
+	}
+	return percent, candles, nil
+}
+
+// checkTimeStop evaluates the holding time rules and returns a CLOSE action
+// when one fires
+func (tm *TierManager) checkTimeStop(pos *ManagedPosition, now time.Time) *TierAction {
+	config := tm.configFor(pos)
+
+	lead := time.Duration(config.FlatLeadMinutes) * time.Minute
+	for _, window := range config.FlatWindows {
+		if window.Contains(now) || window.Contains(now.Add(lead)) {
+			return &TierAction{
+				Type:   "CLOSE",
+				Reason: fmt.Sprintf("⏰ Time Stop: %s", window),
+			}
+		}
+	}
+
+	if config.CandleDuration <= 0 {
+		return nil
+	}
+	held := int(now.Sub(pos.EntryTime) / config.CandleDuration)
+
+	if config.MaxHoldCandles > 0 && held >= config.MaxHoldCandles {
+		return &TierAction{
+			Type:   "CLOSE",
+			Reason: fmt.Sprintf("⏰ Time Stop: Held %d candles (max %d)", held, config.MaxHoldCandles),
+		}
+	}
+
+	if config.MinProfitCandles > 0 && held >= config.MinProfitCandles {
+		if profitPct := pos.GetCurrentProfitPct(); profitPct < config.MinProfitPercent {
+			return &TierAction{
+				Type: "CLOSE",
+				Reason: fmt.Sprintf("⏰ Time Stop: %+.2f%% after %d candles (need +%.2f%%)",
+					profitPct, held, config.MinProfitPercent),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package trademanager
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Trailing stop modes for Config.TrailMode
+const (
+	TRAIL_PROFIT_LOCK = "profit_lock" // Lock a share of the max move after time in profit
+	TRAIL_ATR         = "atr"         // Chandelier: best price since entry +/- ATR multiple
+	TRAIL_SWING       = "swing"       // Last confirmed swing pivot
+	TRAIL_PSAR        = "psar"        // Parabolic SAR
+)
+
+// Bar is one candle of the market data fed to the manager
+type Bar struct {
+	Time  time.Time // Open time
+	Open  float64
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// UpdateMarketData stores recent candles (oldest first) and the current ATR
+// for a position; the trailing modes use them on the next UpdatePrice
+func (m *Manager) UpdateMarketData(symbol string, bars []Bar, atr float64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	pos, exists := m.positions[symbol]
+	if !exists {
+		return fmt.Errorf("no active position for %s", symbol)
+	}
+
+	pos.Bars = append([]Bar(nil), bars...)
+	pos.ATR = atr
+	return nil
+}
+
+// checkTrailingStop evaluates the ATR, swing and Parabolic SAR modes. The
+// stop only tightens and never crosses the current price.
+func (tm *TierManager) checkTrailingStop(pos *ManagedPosition) *TierAction {
+	config := tm.configFor(pos)
+
+	var stop float64
+	var label string
+	switch config.TrailMode {
+	case TRAIL_ATR:
+		stop = chandelierStop(pos, config.TrailATRMultiple)
+		label = fmt.Sprintf("Chandelier %.1fx ATR", config.TrailATRMultiple)
+	case TRAIL_SWING:
+		stop = swingStop(pos.Side, pos.Bars, config.TrailSwingStrength)
+		label = "Swing Pivot"
+	case TRAIL_PSAR:
+		stop = parabolicSARStop(pos, config.TrailPSARStep, config.TrailPSARMax)
+		label = "Parabolic SAR"
+	default:
+		return nil
+	}
+
+	if stop <= 0 {
+		return nil
+	}
+	if pos.Side == "SHORT" && (stop >= pos.StopLoss || stop <= pos.CurrentPrice) {
+		return nil
+	}
+	if pos.Side != "SHORT" && (stop <= pos.StopLoss || stop >= pos.CurrentPrice) {
+		return nil
+	}
+
+	return &TierAction{
+		Type:          "MOVE_STOP",
+		NewStopLoss:   stop,
+		Reason:        fmt.Sprintf("📏 Tier 3: %s Trail", label),
+		TierActivated: 3,
+	}
+}
+
+// chandelierStop hangs the stop an ATR multiple from the best price since entry
+func chandelierStop(pos *ManagedPosition, multiple float64) float64 {
+	if pos.ATR <= 0 || multiple <= 0 {
+		return 0
+	}
+	if pos.Side == "SHORT" {
+		return pos.LowestPrice + multiple*pos.ATR
+	}
+	return pos.HighestPrice - multiple*pos.ATR
+}
+
+// swingStop returns the most recent pivot high (shorts) or pivot low (longs)
+// with strength bars on each side that don't exceed it
+func swingStop(side string, bars []Bar, strength int) float64 {
+	if strength <= 0 {
+		return 0
+	}
+
+	for i := len(bars) - 1 - strength; i >= strength; i-- {
+		pivot := true
+		for j := i - strength; j <= i+strength && pivot; j++ {
+			if j == i {
+				continue
+			}
+			if side == "SHORT" {
+				pivot = bars[j].High <= bars[i].High
+			} else {
+				pivot = bars[j].Low >= bars[i].Low
+			}
+		}
+		if pivot {
+			if side == "SHORT" {
+				return bars[i].High
+			}
+			return bars[i].Low
+		}
+	}
+	return 0
+}
+
+// parabolicSARStop runs Parabolic SAR in the trade's direction from the bar
+// the position was opened in and returns the SAR for the next bar
+func parabolicSARStop(pos *ManagedPosition, step, maxStep float64) float64 {
+	bars := pos.Bars
+	if step <= 0 || maxStep <= 0 {
+		return 0
+	}
+
+	start := 0
+	for i, bar := range bars {
+		if !bar.Time.After(pos.EntryTime) {
+			start = i
+		}
+	}
+	if start >= len(bars)-1 {
+		return 0 // Need at least one bar after the entry bar
+	}
+
+	short := pos.Side == "SHORT"
+	sar, extreme := bars[start].Low, bars[start].High
+	if short {
+		sar, extreme = bars[start].High, bars[start].Low
+	}
+	af := step
+
+	// next projects the SAR one bar ahead, kept outside the last two bars
+	next := func(i int) float64 {
+		value := sar + af*(extreme-sar)
+		for j := i - 1; j >= start && j >= i-2; j-- {
+			if short {
+				value = math.Max(value, bars[j].High)
+			} else {
+				value = math.Min(value, bars[j].Low)
+			}
+		}
+		return value
+	}
+
+	for i := start + 1; i < len(bars); i++ {
+		sar = next(i)
+		if short && bars[i].Low < extreme {
+			extreme = bars[i].Low
+			af = math.Min(af+step, maxStep)
+		} else if !short && bars[i].High > extreme {
+			extreme = bars[i].High
+			af = math.Min(af+step, maxStep)
+		}
+	}
+	return next(len(bars))
+}
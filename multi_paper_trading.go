@@ -26,9 +26,29 @@ type MultiPaperTradingEngine struct {
 	mutex           sync.Mutex
 	MaxPositions    int // Maximum simultaneous positions
 	Logger          *TradeLogger
-	TradeManager    *trademanager.Manager // 3-Tier trade management system
+	TradeManager    *trademanager.Manager    // 3-Tier trade management system
+	Cooldowns       *CooldownTracker         // Per-symbol re-entry cooldowns
+	Risk            *RiskManager             // Portfolio-level loss limits
+	Sizer           PositionSizer            // Position sizing model (--sizing)
+	Broker          Broker                   // Executes orders (--broker)
+	PendingEntries  map[string]*PendingEntry // symbol -> resting limit entry
+	Correlations    *CorrelationMatrix       // Latest scan's return correlations
 }
 
+// Tier 2 partial exit ladder (set from --exit-ladder; empty = single partial exit)
+var EXIT_LADDER []trademanager.ExitStep
+
+// Trade manager config profile (set from --tm-profile; picks the Tier 3 trail mode)
+var TRADE_MANAGER_PROFILE = trademanager.PROFILE_DEFAULT
+
+// Time stops (set from --max-hold, --min-profit-by and --flat-window; zero = off)
+var (
+	MAX_HOLD_CANDLES   int
+	MIN_PROFIT_PERCENT float64
+	MIN_PROFIT_CANDLES int
+	FLAT_WINDOWS       []SessionWindow
+)
+
 func NewMultiPaperTradingEngine(symbols []string, interval string, limit int, startingBalance float64, maxPositions int) *MultiPaperTradingEngine {
 	if maxPositions == 0 {
 		maxPositions = 5 // Default to 5 simultaneous positions
@@ -42,9 +62,32 @@ func NewMultiPaperTradingEngine(symbols []string, interval string, limit int, st
 	}
 
 	// Initialize 3-Tier trade management system
-	tmConfig := trademanager.DefaultConfig()
+	tmConfig, err := trademanager.ConfigForProfile(TRADE_MANAGER_PROFILE)
+	if err != nil {
+		fmt.Printf("⚠️  %v - using the default profile\n", err)
+		tmConfig = trademanager.DefaultConfig()
+	}
+	tmConfig.Tier2Ladder = EXIT_LADDER
+	tmConfig.MaxHoldCandles = MAX_HOLD_CANDLES
+	tmConfig.MinProfitCandles = MIN_PROFIT_CANDLES
+	tmConfig.MinProfitPercent = MIN_PROFIT_PERCENT
+	for _, window := range FLAT_WINDOWS {
+		tmConfig.FlatWindows = append(tmConfig.FlatWindows, window)
+	}
+	tmConfig.FlatLeadMinutes = FLAT_WINDOW_LEAD_MINUTES
+	if candle, err := parseIntervalDuration(interval); err == nil {
+		tmConfig.CandleDuration = candle
+	}
 	tradeManager := trademanager.NewManager(tmConfig, VERBOSE_MODE)
 
+	// The broker's account balance is the starting point, not --balance
+	broker := newConfiguredBroker(startingBalance)
+	if balance, err := quoteBalance(broker); err != nil {
+		fmt.Printf("⚠️  Broker balance unavailable (%v) - starting from $%.2f\n", err, startingBalance)
+	} else {
+		startingBalance = balance
+	}
+
 	engine := &MultiPaperTradingEngine{
 		Symbols:         symbols,
 		Interval:        interval,
@@ -57,14 +100,22 @@ func NewMultiPaperTradingEngine(symbols []string, interval string, limit int, st
 		MaxPositions:    maxPositions,
 		Logger:          logger,
 		TradeManager:    tradeManager,
+		Cooldowns:       NewCooldownTracker(interval),
+		Risk:            NewRiskManager(DefaultRiskConfig(), startingBalance),
+		Sizer:           newConfiguredSizer(),
+		Broker:          broker,
+		PendingEntries:  make(map[string]*PendingEntry),
 	}
 
 	// Setup trade manager callbacks
 	tradeManager.SetCallbacks(
 		engine.handlePartialExit,
 		engine.handleStopUpdate,
-		nil, // position close callback (optional)
+		engine.handlePositionClose,
 	)
+	tradeManager.SetPriceRounder(func(symbol string, price float64) float64 {
+		return EXCHANGE_FILTERS.Get(symbol).RoundPrice(price)
+	})
 
 	// Display 3-Tier configuration
 	if VERBOSE_MODE {
@@ -80,8 +131,12 @@ func NewMultiPaperTradingEngine(symbols []string, interval string, limit int, st
 		fmt.Printf("   Tier 1: %.1f%% (Breakeven Lock)\n", tmConfig.Tier1BreakevenThreshold)
 		fmt.Printf("   Tier 2: %.1f%% (Partial Exit %.0f%%)\n",
 			tmConfig.Tier2PartialExitThreshold, tmConfig.Tier2PartialExitPercent)
-		fmt.Printf("   Tier 3: %ds (Trailing Stop - Locks %.0f%% of max profit)\n",
-			tmConfig.Tier3TimeThreshold, tmConfig.Tier3ProfitLockPercent)
+		if tmConfig.TrailMode == trademanager.TRAIL_PROFIT_LOCK {
+			fmt.Printf("   Tier 3: %ds (Trailing Stop - Locks %.0f%% of max profit)\n",
+				tmConfig.Tier3TimeThreshold, tmConfig.Tier3ProfitLockPercent)
+		} else {
+			fmt.Printf("   Tier 3: %s trailing stop (%s profile)\n", tmConfig.TrailMode, TRADE_MANAGER_PROFILE)
+		}
 
 		fmt.Println("\n💡 Expected Impact:")
 		fmt.Println("   • Reduced give-back: ~67%")
@@ -92,32 +147,134 @@ func NewMultiPaperTradingEngine(symbols []string, interval string, limit int, st
 	} else {
 		fmt.Println("\n✅ 3-Tier Trade Management: ACTIVE")
 		fmt.Printf("   Engine: %.1f%% SL / %.1f%% TP | %s\n", STOP_LOSS_PERCENT, TAKE_PROFIT_PERCENT, interval)
-		fmt.Printf("   Tiers: %.1f%% BE | %.1f%% Partial | %ds Trailing\n",
-			tmConfig.Tier1BreakevenThreshold, tmConfig.Tier2PartialExitThreshold, tmConfig.Tier3TimeThreshold)
+		fmt.Printf("   Tiers: %.1f%% BE | %.1f%% Partial | %ds Trailing (%s)\n",
+			tmConfig.Tier1BreakevenThreshold, tmConfig.Tier2PartialExitThreshold, tmConfig.Tier3TimeThreshold, tmConfig.TrailMode)
+	}
+	if len(tmConfig.Tier2Ladder) > 0 {
+		fmt.Printf("   Exit Ladder: %v (trigger:exit%% of original size)\n", tmConfig.Tier2Ladder)
+	}
+	if tmConfig.MaxHoldCandles > 0 {
+		fmt.Printf("   Time Stop: close after %d candles\n", tmConfig.MaxHoldCandles)
+	}
+	if tmConfig.MinProfitCandles > 0 {
+		fmt.Printf("   Time Stop: close if under +%.2f%% after %d candles\n", tmConfig.MinProfitPercent, tmConfig.MinProfitCandles)
+	}
+	for _, window := range FLAT_WINDOWS {
+		fmt.Printf("   Time Stop: flat %dm before %s\n", FLAT_WINDOW_LEAD_MINUTES, window.Name)
 	}
 
 	return engine
 }
 
-func (mp *MultiPaperTradingEngine) OpenTrade(symbol, side string, entryPrice, stopLoss, takeProfit, size float64) {
+// OpenTrade opens a paper position, rounded to the exchange filters.
+// Returns false if the trade was not opened.
+func (mp *MultiPaperTradingEngine) OpenTrade(symbol, side string, entryPrice, stopLoss, takeProfit float64, tpMethod string, score, size float64,
+	replace *Replacement) bool {
+	// Round to tick/lot size and reject what the exchange would refuse
+	// (before locking - the filter cache may fetch exchange info)
+	order, err := EXCHANGE_FILTERS.Get(symbol).RoundOrder(side, entryPrice, stopLoss, takeProfit, size)
+	if err != nil {
+		fmt.Printf("   ⛔ [%s] Order rejected: %v\n", symbol, err)
+		return false
+	}
+	entryPrice, stopLoss, takeProfit = order.Entry, order.StopLoss, order.TakeProfit
+
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
 
+	if !mp.canEnter(symbol, replace) {
+		return false
+	}
+
+	// Execute the entry through the broker
+	entry, err := mp.Broker.PlaceOrder(OrderRequest{
+		Symbol:   symbol,
+		Side:     entryOrderSide(side),
+		Type:     ORDER_TYPE_MARKET,
+		Quantity: order.Quantity,
+		Price:    entryPrice,
+	})
+	if err != nil {
+		fmt.Printf("   ⛔ [%s] Entry order failed: %v\n", symbol, err)
+		return false
+	}
+	quantity := order.Quantity
+	if entry.ExecutedQty > 0 && entry.AvgPrice > 0 {
+		entryPrice, quantity = entry.AvgPrice, entry.ExecutedQty
+	}
+
+	trade := mp.registerTrade(symbol, side, entryPrice, quantity, stopLoss, takeProfit, tpMethod, score)
+	trade.EntrySlippagePct = slippagePercent(entry.Side, order.Entry, entryPrice)
+	if entry.Status == ORDER_STATUS_EXPIRED {
+		fmt.Printf("   💧 [%s] Partial fill: %.8f of %.8f (book too thin for the rest)\n",
+			symbol, quantity, order.Quantity)
+	}
+	if trade.EntrySlippagePct != 0 {
+		fmt.Printf("   💧 [%s] Entry slippage: %+.3f%% ($%.4f -> $%.4f)\n",
+			symbol, trade.EntrySlippagePct, order.Entry, entryPrice)
+	}
+	mp.closeReplaced(replace, symbol, score)
+	return true
+}
+
+// canEnter checks that the symbol has no position or pending entry and a slot
+// is free, counting the slot of the trade it replaces (may be nil) as free
+// (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) canEnter(symbol string, replace *Replacement) bool {
 	// Check if already have a trade for this symbol
-	if _, exists := mp.ActiveTrades[symbol]; exists {
+	_, active := mp.ActiveTrades[symbol]
+	_, pending := mp.PendingEntries[symbol]
+	if active || pending {
 		if VERBOSE_MODE {
 			fmt.Printf("⚠️  Already have an open trade for %s\n", symbol)
 		}
-		return
+		return false
+	}
+
+	// Time stops would close a position opened into a flat window right away
+	if window := activeFlatWindow(time.Now()); window != nil {
+		if VERBOSE_MODE {
+			fmt.Printf("⚠️  Inside %s. Skipping %s\n", window.Name, symbol)
+		}
+		return false
 	}
 
 	// Check if we've reached max positions
-	if len(mp.ActiveTrades) >= mp.MaxPositions {
+	used := mp.slotsUsed()
+	if replace != nil {
+		if _, open := mp.ActiveTrades[replace.Symbol]; open && !mp.replacedByPending(replace.Symbol) {
+			used--
+		}
+	}
+	if used >= mp.MaxPositions {
 		if VERBOSE_MODE {
 			fmt.Printf("⚠️  Max positions reached (%d). Skipping %s\n", mp.MaxPositions, symbol)
 		}
-		return
+		return false
 	}
+	return true
+}
+
+// slotsUsed counts open positions plus pending limit entries. A position a
+// pending entry will replace shares that entry's slot. (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) slotsUsed() int {
+	used := len(mp.ActiveTrades) + len(mp.PendingEntries)
+	for _, pending := range mp.PendingEntries {
+		if pending.Replaces == nil {
+			continue
+		}
+		if _, open := mp.ActiveTrades[pending.Replaces.Symbol]; open {
+			used--
+		}
+	}
+	return used
+}
+
+// registerTrade records a filled entry, places its stop and target orders and
+// hands it to the trade manager (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) registerTrade(symbol, side string, entryPrice, quantity, stopLoss, takeProfit float64,
+	tpMethod string, score float64) *PaperTrade {
+	size := quantity * entryPrice
 
 	mp.TradeCounter++
 	trade := PaperTrade{
@@ -129,7 +286,11 @@ func (mp *MultiPaperTradingEngine) OpenTrade(symbol, side string, entryPrice, st
 		EntryTime:    time.Now(),
 		StopLoss:     stopLoss,
 		TakeProfit:   takeProfit,
+		TPMethod:     tpMethod,
+		SignalScore:  score,
 		Size:         size,
+		Quantity:     quantity,
+		Fills:        []TradeFill{{Time: time.Now(), Price: entryPrice, Quantity: quantity}},
 		Status:       "OPEN",
 		HighestPrice: entryPrice, // Initialize to entry price
 		LowestPrice:  entryPrice, // Initialize to entry price
@@ -155,6 +316,7 @@ func (mp *MultiPaperTradingEngine) OpenTrade(symbol, side string, entryPrice, st
 		trade.RiskReward = reward / risk
 	}
 
+	placeExitOrders(mp.Broker, &trade)
 	mp.ActiveTrades[symbol] = &trade
 
 	if VERBOSE_MODE {
@@ -164,7 +326,7 @@ func (mp *MultiPaperTradingEngine) OpenTrade(symbol, side string, entryPrice, st
 		fmt.Printf("\n🔔 Trade #%d: %s %s\n", trade.ID, side, symbol)
 		fmt.Printf("💰 Entry:       $%.2f\n", entryPrice)
 		fmt.Printf("🛑 Stop Loss:   $%.2f (%.2f%%)\n", stopLoss, (risk/entryPrice)*100)
-		fmt.Printf("🎯 Take Profit: $%.2f (%.2f%%)\n", takeProfit, (reward/entryPrice)*100)
+		fmt.Printf("🎯 Take Profit: $%.2f (%.2f%%, %s)\n", takeProfit, (reward/entryPrice)*100, tpMethod)
 		fmt.Printf("📊 Size:        $%.2f\n", size)
 		fmt.Printf("⚖️  Risk/Reward: %.2f:1\n", trade.RiskReward)
 	} else {
@@ -187,12 +349,36 @@ func (mp *MultiPaperTradingEngine) OpenTrade(symbol, side string, entryPrice, st
 			size,
 		)
 	}
+	return &trade
 }
 
-func (mp *MultiPaperTradingEngine) CheckAndClosePositions(currentPrices map[string]float64) {
+// updateMarketData feeds a symbol's recent candles and ATR to the trade
+// manager for the candle-based trailing stops
+func (mp *MultiPaperTradingEngine) updateMarketData(symbol string, candles []Candle) {
+	bars := make([]trademanager.Bar, len(candles))
+	for i, c := range candles {
+		bars[i] = trademanager.Bar{Time: c.OpenTime, Open: c.Open, High: c.High, Low: c.Low, Close: c.Close}
+	}
+	atr := lastValue(calcATR(candles, TRAIL_ATR_PERIOD))
+	if err := mp.TradeManager.UpdateMarketData(symbol, bars, atr); err != nil && VERBOSE_MODE {
+		fmt.Printf("⚠️  Trade manager error for %s: %v\n", symbol, err)
+	}
+}
+
+// CheckAndClosePositions matches resting orders against the latest candles
+// (simulated brokers), runs the 3-tier manager and closes positions at SL/TP.
+// candles holds each symbol's recent time-based candles, oldest first.
+func (mp *MultiPaperTradingEngine) CheckAndClosePositions(currentPrices map[string]float64, candles map[string][]Candle) {
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
 
+	// Simulated brokers fill entries, stops and targets from the candle range
+	matcher, simulated := mp.Broker.(OrderMatcher)
+	if simulated {
+		mp.matchOrders(matcher, candles)
+	}
+	mp.expirePendingEntries()
+
 	for symbol, trade := range mp.ActiveTrades {
 		currentPrice, exists := currentPrices[symbol]
 		if !exists {
@@ -201,11 +387,22 @@ func (mp *MultiPaperTradingEngine) CheckAndClosePositions(currentPrices map[stri
 
 		// Update trade manager with current price (evaluates 3-Tier rules)
 		if mp.TradeManager != nil && mp.TradeManager.IsEnabled() {
+			if recent := candles[symbol]; len(recent) > 0 {
+				mp.updateMarketData(symbol, recent)
+			}
 			if err := mp.TradeManager.UpdatePrice(symbol, currentPrice); err != nil {
 				if VERBOSE_MODE {
 					fmt.Printf("⚠️  Trade manager error for %s: %v\n", symbol, err)
 				}
 			}
+			if _, open := mp.ActiveTrades[symbol]; !open {
+				continue // Closed by a time stop
+			}
+		}
+
+		// Add to the position on a zone retest (--scale-in)
+		if recent := candles[symbol]; len(recent) > 0 {
+			mp.checkScaleIn(trade, recent[len(recent)-1], currentPrices)
 		}
 
 		// Track highest and lowest prices
@@ -232,6 +429,12 @@ func (mp *MultiPaperTradingEngine) CheckAndClosePositions(currentPrices map[stri
 			trade.MaxProfitPct = currentProfitPct
 		}
 
+		// Stop and target orders resting with a simulated broker close the trade
+		// in matchOrders; otherwise compare prices here
+		if simulated && trade.StopOrderID != "" && trade.TakeProfitOrderID != "" {
+			continue
+		}
+
 		shouldClose := false
 		closeReason := ""
 
@@ -265,6 +468,44 @@ func (mp *MultiPaperTradingEngine) closeTradeInternal(symbol string, exitPrice f
 		return
 	}
 
+	// Cancel the stop and target orders and exit through the broker
+	cancelExitOrders(mp.Broker, trade)
+	exit, err := mp.Broker.PlaceOrder(OrderRequest{
+		Symbol:     symbol,
+		Side:       exitOrderSide(trade.Side),
+		Type:       ORDER_TYPE_MARKET,
+		Quantity:   trade.Quantity,
+		Price:      exitPrice,
+		ReduceOnly: true,
+	})
+	if err != nil {
+		if brokerHasPosition(mp.Broker, symbol) {
+			fmt.Printf("⚠️  [%s] Exit order failed: %v - position stays open\n", symbol, err)
+			placeExitOrders(mp.Broker, trade)
+			return
+		}
+		// Already flat at the broker (e.g., the exchange stop filled) - book it at the last price
+		fmt.Printf("⚠️  [%s] Exit order failed (%v) but the broker shows no position - closing\n", symbol, err)
+	} else if exit.AvgPrice > 0 {
+		trade.ExitSlippagePct = slippagePercent(exit.Side, exitPrice, exit.AvgPrice)
+		if trade.ExitSlippagePct != 0 {
+			fmt.Printf("   💧 [%s] Exit slippage: %+.3f%% ($%.4f -> $%.4f)\n",
+				symbol, trade.ExitSlippagePct, exitPrice, exit.AvgPrice)
+		}
+		exitPrice = exit.AvgPrice
+	}
+
+	mp.recordClose(symbol, exitPrice, reason)
+}
+
+// recordClose books a position whose exit has already executed: P/L, stats,
+// CSV and trade manager cleanup (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) recordClose(symbol string, exitPrice float64, reason string) {
+	trade, exists := mp.ActiveTrades[symbol]
+	if !exists {
+		return
+	}
+
 	trade.ExitPrice = exitPrice
 	trade.ExitTime = time.Now()
 
@@ -276,28 +517,41 @@ func (mp *MultiPaperTradingEngine) closeTradeInternal(symbol string, exitPrice f
 
 	trade.ProfitLossPct = (trade.ProfitLoss / trade.Size) * 100
 
-	if trade.ProfitLoss > 0 {
+	// Partial exits count towards the result, so a ladder trade stopped at
+	// breakeven is a win
+	netProfit := trade.NetProfitLoss()
+	if netProfit > 0 {
 		mp.WinCount++
-		mp.TotalProfit += trade.ProfitLoss
+		mp.TotalProfit += netProfit
 		if reason == "STOP_LOSS" {
 			trade.Status = "CLOSED_SL_WIN"
+		} else if reason == "REPLACED" {
+			trade.Status = "CLOSED_REPLACED"
 		} else if reason == "TAKE_PROFIT" {
 			trade.Status = "CLOSED_TP"
+		} else if reason == trademanager.CLOSE_TIME_STOP {
+			trade.Status = "TIME_STOP"
 		} else {
 			trade.Status = "CLOSED_WIN"
 		}
 	} else {
 		mp.LossCount++
-		mp.TotalLoss += trade.ProfitLoss
+		mp.TotalLoss += netProfit
 		if reason == "STOP_LOSS" {
 			trade.Status = "CLOSED_SL"
+		} else if reason == "REPLACED" {
+			trade.Status = "CLOSED_REPLACED"
+		} else if reason == trademanager.CLOSE_TIME_STOP {
+			trade.Status = "TIME_STOP"
 		} else {
 			trade.Status = "CLOSED_LOSS"
 		}
 	}
 
-	mp.CurrentBalance += trade.ProfitLoss
+	mp.syncBalance()
 	mp.Trades = append(mp.Trades, *trade)
+	mp.Cooldowns.RecordClose(trade, reason)
+	mp.Risk.RecordTradeResult(netProfit)
 
 	// Log trade to CSV
 	if mp.Logger != nil {
@@ -375,6 +629,59 @@ func (mp *MultiPaperTradingEngine) closeTradeInternal(symbol string, exitPrice f
 	}
 }
 
+// Equity returns the broker balance (as of the last sync) plus unrealized P/L
+// of open positions at the given prices (positions without a price count at entry)
+func (mp *MultiPaperTradingEngine) Equity(currentPrices map[string]float64) float64 {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	return mp.equity(currentPrices)
+}
+
+// syncBalance takes the balance from the broker, the source of truth for
+// realized P/L, keeping the last known balance if it can't be read
+// (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) syncBalance() {
+	balance, err := quoteBalance(mp.Broker)
+	if err != nil {
+		fmt.Printf("⚠️  Balance sync failed: %v - keeping $%.2f\n", err, mp.CurrentBalance)
+		return
+	}
+	mp.CurrentBalance = balance
+}
+
+// equity is Equity for callers that hold mp.mutex
+func (mp *MultiPaperTradingEngine) equity(currentPrices map[string]float64) float64 {
+	equity := mp.CurrentBalance
+	for symbol, trade := range mp.ActiveTrades {
+		price, exists := currentPrices[symbol]
+		if !exists {
+			continue
+		}
+		if trade.Side == "SHORT" {
+			equity += (trade.EntryPrice - price) * (trade.Size / trade.EntryPrice)
+		} else {
+			equity += (price - trade.EntryPrice) * (trade.Size / trade.EntryPrice)
+		}
+	}
+	return equity
+}
+
+// FlattenAll closes every open position at the given prices
+func (mp *MultiPaperTradingEngine) FlattenAll(currentPrices map[string]float64, reason string) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	for symbol := range mp.ActiveTrades {
+		price, exists := currentPrices[symbol]
+		if !exists {
+			fmt.Printf("⚠️  [%s] No price - cannot flatten, position stays open\n", symbol)
+			continue
+		}
+		mp.closeTradeInternal(symbol, price, reason)
+	}
+}
+
 // ShowUnrealizedPL displays unrealized P/L for all active positions
 func (mp *MultiPaperTradingEngine) ShowUnrealizedPL(currentPrices map[string]float64) {
 	mp.mutex.Lock()
@@ -440,11 +747,14 @@ func (mp *MultiPaperTradingEngine) ShowUnrealizedPL(currentPrices map[string]flo
 	fmt.Printf("💵 Potential Balance: $%.2f\n", potentialBalance)
 }
 
-func (mp *MultiPaperTradingEngine) fetchPricesParallel(symbols []string) map[string]float64 {
+// fetchPricesParallel returns each symbol's last price and its last TRAIL_BARS
+// time-based candles (the latest drives order matching, the rest the trailing stops)
+func (mp *MultiPaperTradingEngine) fetchPricesParallel(symbols []string) (map[string]float64, map[string][]Candle) {
 	type priceResult struct {
-		symbol string
-		price  float64
-		err    error
+		symbol  string
+		price   float64
+		candles []Candle
+		err     error
 	}
 
 	var wg sync.WaitGroup
@@ -462,10 +772,18 @@ func (mp *MultiPaperTradingEngine) fetchPricesParallel(symbols []string) map[str
 
 			engine := NewTradingEngine(sym, mp.Interval, mp.Limit)
 			if err := engine.FetchData(); err == nil && len(engine.Candles) > 0 {
+				candles := engine.RawCandles
+				if len(candles) == 0 {
+					candles = engine.Candles
+				}
+				if len(candles) > TRAIL_BARS {
+					candles = candles[len(candles)-TRAIL_BARS:]
+				}
 				resultsChan <- priceResult{
-					symbol: sym,
-					price:  engine.Candles[len(engine.Candles)-1].Close,
-					err:    nil,
+					symbol:  sym,
+					price:   engine.LastPrice(),
+					candles: append([]Candle(nil), candles...),
+					err:     nil,
 				}
 			} else {
 				resultsChan <- priceResult{
@@ -485,13 +803,15 @@ func (mp *MultiPaperTradingEngine) fetchPricesParallel(symbols []string) map[str
 
 	// Collect results
 	prices := make(map[string]float64)
+	candles := make(map[string][]Candle)
 	for result := range resultsChan {
 		if result.err == nil && result.price > 0 {
 			prices[result.symbol] = result.price
+			candles[result.symbol] = result.candles
 		}
 	}
 
-	return prices
+	return prices, candles
 }
 
 func (mp *MultiPaperTradingEngine) PrintPortfolio() {
@@ -538,6 +858,17 @@ func (mp *MultiPaperTradingEngine) PrintPortfolio() {
 		}
 	}
 
+	if len(mp.PendingEntries) > 0 {
+		fmt.Println("\n📌 Pending Limit Entries:")
+		for symbol, pending := range mp.PendingEntries {
+			fmt.Printf("  %s: %s LIMIT @ $%.4f (expires in %.0fm)\n",
+				symbol, pending.Side, pending.LimitPrice, time.Until(pending.ExpiresAt).Minutes())
+		}
+	}
+
+	mp.Cooldowns.Print()
+	mp.Risk.Print()
+
 	fmt.Println("════════════════════════════════════════")
 }
 
@@ -597,143 +928,62 @@ func (mp *MultiPaperTradingEngine) RunMultiPaperTrading() error {
 
 		// Analyze all symbols in parallel
 		results := RunMultiSymbolAnalysis(mp.Symbols, mp.Interval, mp.Limit)
+		correlations := CorrelationFromResults(results)
+		mp.mutex.Lock()
+		mp.Correlations = correlations
+		mp.mutex.Unlock()
 
 		// Collect current prices for position management IN PARALLEL
-		currentPrices := mp.fetchPricesParallel(mp.Symbols)
+		currentPrices, latestCandles := mp.fetchPricesParallel(mp.Symbols)
 
 		// Check and close positions that hit SL/TP
-		mp.CheckAndClosePositions(currentPrices)
+		mp.CheckAndClosePositions(currentPrices, latestCandles)
+
+		// Reconcile with the broker's balance (fills may happen outside the
+		// engine, e.g. exchange-side stops), then apply the portfolio risk
+		// limits on realized + unrealized equity
+		mp.mutex.Lock()
+		mp.syncBalance()
+		mp.mutex.Unlock()
+		if mp.Risk.Update(mp.Equity(currentPrices), time.Now()) == RISK_ACTION_FLATTEN {
+			mp.FlattenAll(currentPrices, "RISK_FLATTEN")
+		}
 
-		// Look for new trade signals
+		// Evaluate every signal first, then allocate slots best-first
+		// (skipped while the session filter or risk manager blocks entries)
 		newSignals := 0
-		for _, result := range results {
-			if result.HasSignal && result.Error == nil {
-				// Check if we already have a position for this symbol
+		if sessionAllowsEntries(time.Now()) && riskAllowsEntries(mp.Risk) {
+			var candidates []SignalCandidate
+			for _, result := range results {
+				if !result.HasSignal || result.Error != nil {
+					continue
+				}
+
+				// Check if we already have a position or limit entry for this symbol
 				mp.mutex.Lock()
 				_, hasPosition := mp.ActiveTrades[result.Symbol]
-				canOpenMore := len(mp.ActiveTrades) < mp.MaxPositions
+				_, hasPending := mp.PendingEntries[result.Symbol]
 				mp.mutex.Unlock()
+				if hasPosition || hasPending {
+					continue
+				}
 
-				if !hasPosition && canOpenMore {
-					// Fetch detailed data for this symbol
-					engine := NewOptimizedEngine(result.Symbol, mp.Interval, mp.Limit)
-					if err := engine.FetchData(); err != nil {
-						continue
-					}
-
-					engine.CalculateIndicators()
-					engine.FindDivergences()
-					engine.IdentifySupportResistance()
-
-					if len(engine.Candles) == 0 || len(engine.RSI) == 0 {
-						continue
-					}
-
-					currentPrice := engine.Candles[len(engine.Candles)-1].Close
-					currentRSI := engine.RSI[len(engine.RSI)-1]
-
-					// Find nearest resistance and support
-					var nearestResistance *SRZone
-					minDistanceUp := 1000000.0
-					for i := range engine.SRZones {
-						if engine.SRZones[i].Level > currentPrice {
-							distance := engine.SRZones[i].Level - currentPrice
-							if distance < minDistanceUp {
-								minDistanceUp = distance
-								nearestResistance = &engine.SRZones[i]
-							}
-						}
-					}
-
-					var nearestSupport *SRZone
-					minDistanceDown := 1000000.0
-					for i := range engine.SRZones {
-						if engine.SRZones[i].Level < currentPrice {
-							distance := currentPrice - engine.SRZones[i].Level
-							if distance < minDistanceDown {
-								minDistanceDown = distance
-								nearestSupport = &engine.SRZones[i]
-							}
-						}
-					}
-
-					entry := currentPrice
-					var stopLoss, takeProfit float64
-
-					if nearestResistance != nil {
-						stopLoss = nearestResistance.ZoneTop
-						if VERBOSE_MODE {
-							fmt.Printf("   🎯 [%s] Using resistance zone SL: $%.4f (zone: $%.4f-$%.4f)\n",
-								result.Symbol, stopLoss, nearestResistance.ZoneBot, nearestResistance.ZoneTop)
-						}
-					} else {
-						stopLoss = currentPrice * (1 + STOP_LOSS_PERCENT/100)
-						if VERBOSE_MODE {
-							fmt.Printf("   🎯 [%s] No resistance zone, using fixed SL: $%.4f (+%.2f%%)\n",
-								result.Symbol, stopLoss, STOP_LOSS_PERCENT)
-						}
-					}
-
-					if nearestSupport != nil {
-						takeProfit = nearestSupport.ZoneBot
-						if VERBOSE_MODE {
-							fmt.Printf("   🎯 [%s] Using support zone TP: $%.4f (zone: $%.4f-$%.4f)\n",
-								result.Symbol, takeProfit, nearestSupport.ZoneBot, nearestSupport.ZoneTop)
-						}
-					} else {
-						takeProfit = currentPrice * (1 - TAKE_PROFIT_PERCENT/100)
-						if VERBOSE_MODE {
-							fmt.Printf("   🎯 [%s] No support zone, using fixed TP: $%.4f (-%.2f%%)\n",
-								result.Symbol, takeProfit, TAKE_PROFIT_PERCENT)
-						}
-					}
-
-					// CRITICAL FIX: Ensure SL is always ABOVE entry for SHORT
-					if stopLoss <= entry {
-						stopLoss = entry * (1 + STOP_LOSS_PERCENT/100)
-						if VERBOSE_MODE {
-							fmt.Printf("   ⚠️  [%s] WARNING: SL was at/below entry! Adjusted to $%.4f (+%.2f%%)\n",
-								result.Symbol, stopLoss, STOP_LOSS_PERCENT)
-						}
-					}
-
-					// CRITICAL FIX: Ensure TP is always BELOW entry for SHORT
-					if takeProfit >= entry {
-						takeProfit = entry * (1 - TAKE_PROFIT_PERCENT/100)
-						if VERBOSE_MODE {
-							fmt.Printf("   ⚠️  [%s] WARNING: TP was at/above entry! Adjusted to $%.4f (-%.2f%%)\n",
-								result.Symbol, takeProfit, TAKE_PROFIT_PERCENT)
-						}
+				if cooldown := mp.Cooldowns.Active(result.Symbol, time.Now()); cooldown != nil {
+					if VERBOSE_MODE {
+						fmt.Printf("   🧊 [%s] In cooldown for %s after %s\n",
+							result.Symbol, cooldown.Remaining(time.Now()).Round(time.Second), cooldown.Reason)
 					}
+					continue
+				}
 
-					risk := stopLoss - entry
-					reward := entry - takeProfit
-					rr := reward / risk
-
-					if rr >= RISK_REWARD_RATIO && currentRSI > 70 {
-						// ✅ FIXED: Use simple fixed allocation (realistic for 1x leverage)
-						// Each trade gets equal share of initial balance
-						positionSize := mp.StartingBalance / float64(mp.MaxPositions)
-
-						// Optional: Log if risk-based sizing would have been larger (for analysis)
-						riskAmount := mp.CurrentBalance * (MAX_RISK_PERCENT / 100)
-						riskPercentPrice := (risk / entry) * 100
-						riskBasedSize := riskAmount / (riskPercentPrice / 100)
-
-						if riskBasedSize > positionSize && VERBOSE_MODE {
-							fmt.Printf("   ⚠️  [%s] Risk-based size $%.0f capped to $%.0f (1x leverage)\n",
-								result.Symbol, riskBasedSize, positionSize)
-						}
-
-						fmt.Printf("\n🎯 SIGNAL: %s (RSI: %.2f, Div: %d, R/R: %.2f:1)\n",
-							result.Symbol, currentRSI, result.Divergences, rr)
-
-						mp.OpenTrade(result.Symbol, "SHORT", entry, stopLoss, takeProfit, positionSize)
-						newSignals++
-					}
+				if candidate := mp.evaluateCandidate(result); candidate != nil {
+					candidates = append(candidates, *candidate)
 				}
 			}
+
+			newSignals = mp.allocateSlots(candidates, currentPrices, correlations)
 		}
+		drainBrokerEvents(mp.Broker)
 
 		if newSignals > 0 {
 			fmt.Printf("\n✅ Opened %d new position(s)\n", newSignals)
@@ -766,47 +1016,169 @@ func (mp *MultiPaperTradingEngine) RunMultiPaperTrading() error {
 
 // ==================== 3-TIER TRADE MANAGER CALLBACKS ====================
 
-// handlePartialExit is called by the trade manager when Tier 2 triggers
-func (mp *MultiPaperTradingEngine) handlePartialExit(symbol string, exitPercent, currentPrice float64) (float64, error) {
+// handlePartialExit is called by the trade manager when Tier 2 triggers and
+// returns the executed quantity and fill price
+func (mp *MultiPaperTradingEngine) handlePartialExit(symbol string, exitPercent, currentPrice float64) (float64, float64, error) {
 	trade, exists := mp.ActiveTrades[symbol]
 	if !exists {
-		return 0, fmt.Errorf("no active trade for %s", symbol)
+		return 0, 0, fmt.Errorf("no active trade for %s", symbol)
 	}
 
-	// Calculate exit size
-	exitSize := trade.Size * (exitPercent / 100.0)
+	// Reduce through the broker in whole lot steps
+	quantity := EXCHANGE_FILTERS.Get(symbol).RoundQuantity(trade.Quantity * (exitPercent / 100.0))
+	order, err := mp.Broker.PlaceOrder(OrderRequest{
+		Symbol:     symbol,
+		Side:       exitOrderSide(trade.Side),
+		Type:       ORDER_TYPE_MARKET,
+		Quantity:   quantity,
+		Price:      currentPrice,
+		ReduceOnly: true,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if order.ExecutedQty > 0 {
+		quantity = order.ExecutedQty
+	}
+	if order.AvgPrice > 0 {
+		currentPrice = order.AvgPrice
+	}
+
+	// Size is notional at entry, so the exited share is measured at entry too
+	exitSize := quantity * trade.EntryPrice
 
 	// Calculate profit from this partial exit
 	var exitProfit float64
 	if trade.Side == "SHORT" {
-		exitProfit = (trade.EntryPrice - currentPrice) * (exitSize / trade.EntryPrice)
+		exitProfit = (trade.EntryPrice - currentPrice) * quantity
 	} else { // LONG
-		exitProfit = (currentPrice - trade.EntryPrice) * (exitSize / trade.EntryPrice)
+		exitProfit = (currentPrice - trade.EntryPrice) * quantity
 	}
 
 	// Update position size
 	trade.Size -= exitSize
-	mp.CurrentBalance += exitProfit
+	trade.Quantity -= quantity
+	mp.syncBalance()
+	trade.PartialExits = append(trade.PartialExits, TradeExit{
+		Time:     time.Now(),
+		Price:    currentPrice,
+		Quantity: quantity,
+		Profit:   exitProfit,
+	})
 
 	if VERBOSE_MODE {
 		fmt.Printf("💰 Partial Exit: %.0f%% of %s @ $%.4f | Profit: $%.4f | Remaining: $%.2f\n",
 			exitPercent, symbol, currentPrice, exitProfit, trade.Size)
 	}
 
-	return exitProfit, nil
+	return quantity, currentPrice, nil
 }
 
-// handleStopUpdate is called by the trade manager when stops need to be moved
+// handlePositionClose is called by the trade manager when a time stop fires
+// (the manager has already dropped the position)
+func (mp *MultiPaperTradingEngine) handlePositionClose(symbol, reason string, currentPrice float64) error {
+	if _, exists := mp.ActiveTrades[symbol]; !exists {
+		return fmt.Errorf("no active trade for %s", symbol)
+	}
+
+	mp.closeTradeInternal(symbol, currentPrice, reason)
+	if _, open := mp.ActiveTrades[symbol]; open {
+		return fmt.Errorf("exit order for %s did not execute", symbol)
+	}
+	return nil
+}
+
+// handleStopUpdate is called by the trade manager when stops need to be moved.
+// The broker's stop order is canceled and replaced at the new price for the
+// remaining quantity, staying in the OCO group with the target.
 func (mp *MultiPaperTradingEngine) handleStopUpdate(symbol string, newStopLoss float64) error {
 	trade, exists := mp.ActiveTrades[symbol]
 	if !exists {
 		return fmt.Errorf("no active trade for %s", symbol)
 	}
 
+	cancelOrder(mp.Broker, trade.Symbol, &trade.StopOrderID)
 	trade.StopLoss = newStopLoss
+	trade.StopOrderID = placeStopOrder(mp.Broker, trade)
 	return nil
 }
 
+// ocoGroup links a trade's stop and target orders
+func ocoGroup(trade *PaperTrade) string {
+	return fmt.Sprintf("trade-%d", trade.ID)
+}
+
+// placeExitOrders places the trade's reduce-only stop and target as an OCO pair
+func placeExitOrders(broker Broker, trade *PaperTrade) {
+	trade.StopOrderID = placeStopOrder(broker, trade)
+
+	order, err := broker.PlaceOrder(OrderRequest{
+		Symbol:     trade.Symbol,
+		Side:       exitOrderSide(trade.Side),
+		Type:       ORDER_TYPE_LIMIT,
+		Quantity:   trade.Quantity,
+		Price:      trade.TakeProfit,
+		ReduceOnly: true,
+		OCOGroup:   ocoGroup(trade),
+	})
+	if err != nil {
+		fmt.Printf("⚠️  [%s] Take profit order failed: %v\n", trade.Symbol, err)
+		return
+	}
+	trade.TakeProfitOrderID = order.ID
+}
+
+// placeStopOrder places a reduce-only stop at the trade's stop loss and returns
+// its order ID ("" if the broker refused - the engine still checks the stop itself)
+func placeStopOrder(broker Broker, trade *PaperTrade) string {
+	order, err := broker.PlaceOrder(OrderRequest{
+		Symbol:     trade.Symbol,
+		Side:       exitOrderSide(trade.Side),
+		Type:       ORDER_TYPE_STOP_MARKET,
+		Quantity:   trade.Quantity,
+		StopPrice:  trade.StopLoss,
+		ReduceOnly: true,
+		OCOGroup:   ocoGroup(trade),
+	})
+	if err != nil {
+		fmt.Printf("⚠️  [%s] Stop order failed: %v\n", trade.Symbol, err)
+		return ""
+	}
+	return order.ID
+}
+
+// cancelExitOrders cancels the trade's stop and target orders, if any
+func cancelExitOrders(broker Broker, trade *PaperTrade) {
+	cancelOrder(broker, trade.Symbol, &trade.StopOrderID)
+	cancelOrder(broker, trade.Symbol, &trade.TakeProfitOrderID)
+}
+
+// cancelOrder cancels a broker order and clears its ID
+func cancelOrder(broker Broker, symbol string, orderID *string) {
+	if *orderID == "" {
+		return
+	}
+	if err := broker.CancelOrder(symbol, *orderID); err != nil && VERBOSE_MODE {
+		fmt.Printf("⚠️  [%s] Cancel order %s: %v\n", symbol, *orderID, err)
+	}
+	*orderID = ""
+}
+
+// brokerHasPosition reports whether the broker still holds a position in symbol
+// (assumes it does when positions can't be read)
+func brokerHasPosition(broker Broker, symbol string) bool {
+	positions, err := broker.GetPositions()
+	if err != nil {
+		return true
+	}
+	for _, pos := range positions {
+		if pos.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
+
 func RunMultiPaperTrading() {
 	// This is called from main when --multi-paper flag is used
 	// Implementation will be added to binance_fetcher.go
@@ -13,47 +13,63 @@ import (
 // ==================== BINANCE SYMBOL FETCHING ====================
 
 type BinanceSymbolInfo struct {
-	Symbol     string `json:"symbol"`
-	Status     string `json:"status"`
-	BaseAsset  string `json:"baseAsset"`
-	QuoteAsset string `json:"quoteAsset"`
+	Symbol     string                `json:"symbol"`
+	Status     string                `json:"status"`
+	BaseAsset  string                `json:"baseAsset"`
+	QuoteAsset string                `json:"quoteAsset"`
+	Filters    []BinanceSymbolFilter `json:"filters"`
 }
 
 type BinanceExchangeInfo struct {
 	Symbols []BinanceSymbolInfo `json:"symbols"`
 }
 
-// FetchAllBinanceSymbols retrieves all trading pairs from Binance
-func FetchAllBinanceSymbols() ([]string, error) {
-	baseURL := GetBaseURL()
-	var endpoint string
-
+// exchangeInfoEndpoint returns the exchange info path for the active market
+func exchangeInfoEndpoint() string {
 	if USE_FUTURES {
-		endpoint = "/fapi/v1/exchangeInfo"
-		fmt.Println("🔄 Fetching exchange info from Binance Futures...")
-	} else {
-		endpoint = "/api/v3/exchangeInfo"
-		fmt.Println("🔄 Fetching exchange info from Binance Spot...")
+		return "/fapi/v1/exchangeInfo"
 	}
+	return "/api/v3/exchangeInfo"
+}
 
-	url := baseURL + endpoint
+// fetchExchangeInfo downloads symbol rules for the active market
+func fetchExchangeInfo() (BinanceExchangeInfo, error) {
+	var exchangeInfo BinanceExchangeInfo
 
-	resp, err := http.Get(url)
+	resp, err := http.Get(GetBaseURL() + exchangeInfoEndpoint())
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch exchange info: %w", err)
+		return exchangeInfo, fmt.Errorf("failed to fetch exchange info: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return exchangeInfo, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	var exchangeInfo BinanceExchangeInfo
 	if err := json.Unmarshal(body, &exchangeInfo); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		return exchangeInfo, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
+	return exchangeInfo, nil
+}
+
+// FetchAllBinanceSymbols retrieves all trading pairs from Binance
+func FetchAllBinanceSymbols() ([]string, error) {
+	if USE_FUTURES {
+		fmt.Println("🔄 Fetching exchange info from Binance Futures...")
+	} else {
+		fmt.Println("🔄 Fetching exchange info from Binance Spot...")
+	}
+
+	exchangeInfo, err := fetchExchangeInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the symbol filters while we have them
+	EXCHANGE_FILTERS.Store(exchangeInfo)
+
 	var symbols []string
 	for _, s := range exchangeInfo.Symbols {
 		// For futures, filter by PERPETUAL contract type if available
@@ -149,6 +165,9 @@ type MultiSymbolResult struct {
 	CurrentRSI  float64
 	HasSignal   bool
 	SignalType  string
+	Regime      string
+	Score       float64  // 0-100 signal confidence (0 if no signal)
+	Candles     []Candle // Last CORRELATION_LOOKBACK+1 candles for return correlation
 	Error       error
 	Duration    time.Duration
 }
@@ -206,20 +225,29 @@ func RunMultiSymbolAnalysis(symbols []string, interval string, limit int) []Mult
 			if len(engine.RSI) > 0 {
 				result.CurrentRSI = engine.RSI[len(engine.RSI)-1]
 			}
+			result.Regime = engine.CurrentRegime()
+			result.Candles = tailCandles(engine.RawCandles, CORRELATION_LOOKBACK+1)
 
 			// Check for trading signals
-			recentDivergences := 0
-			for _, div := range engine.Divergences {
-				divTime, _ := time.Parse("2006-01-02 15:04", div.EndTime)
-				hoursSince := time.Since(divTime).Hours()
-				if hoursSince < 72 {
-					recentDivergences++
+			recentDivergences, _, _ := recentDivergenceStats(engine.Divergences, SIGNAL_SCORE_CONFIG.RecencyHours)
+
+			if recentDivergences >= MIN_DIVERGENCES_FOR_SIGNAL && result.CurrentRSI > 70 &&
+				strategyEnabled(STRATEGY_DIVERGENCE_FADE, result.Regime) {
+				price := engine.LastPrice()
+				nearestResistance, nearestSupport := engine.nearestZones(price)
+				stopLoss, _ := engine.selectStopLoss("SHORT", price, nearestResistance)
+				takeProfit, _ := engine.selectTakeProfit("SHORT", price, stopLoss, nearestSupport)
+				rr := 0.0
+				if stopLoss > price {
+					rr = (price - takeProfit) / (stopLoss - price)
 				}
-			}
 
-			if recentDivergences >= MIN_DIVERGENCES_FOR_SIGNAL && result.CurrentRSI > 70 {
-				result.HasSignal = true
-				result.SignalType = "SHORT"
+				score := engine.ScoreShortSignal(rr, nearestResistance)
+				result.Score = score.Total
+				if score.MeetsMinimum() {
+					result.HasSignal = true
+					result.SignalType = "SHORT"
+				}
 			}
 
 			result.Duration = time.Since(start)
@@ -275,9 +303,21 @@ func RunMultiSymbolAnalysis(symbols []string, interval string, limit int) []Mult
 		fmt.Printf("\n✅ Analysis complete (%.1fs)\n", totalDuration.Seconds())
 	}
 
+	SortResultsByScore(results)
 	return results
 }
 
+// SortResultsByScore orders results by signal score, highest first
+func SortResultsByScore(results []MultiSymbolResult) {
+	for i := 0; i < len(results)-1; i++ {
+		for j := i + 1; j < len(results); j++ {
+			if results[j].Score > results[i].Score {
+				results[i], results[j] = results[j], results[i]
+			}
+		}
+	}
+}
+
 // PrintMultiSymbolResults displays analysis results and highlights signals
 func PrintMultiSymbolResults(results []MultiSymbolResult) {
 	if VERBOSE_MODE {
@@ -298,8 +338,10 @@ func PrintMultiSymbolResults(results []MultiSymbolResult) {
 				fmt.Printf("   📈 Divergences: %d\n", r.Divergences)
 				fmt.Printf("   🎯 S/R Zones: %d\n", r.SRZones)
 				fmt.Printf("   📉 Signal: %s\n", r.SignalType)
+				fmt.Printf("   🧭 Regime: %s\n", r.Regime)
+				fmt.Printf("   🏅 Score: %.0f/100\n", r.Score)
 			} else {
-				fmt.Printf("   🔔 %s (%s signal, RSI: %.1f)\n", r.Symbol, r.SignalType, r.CurrentRSI)
+				fmt.Printf("   🔔 %s (%s signal, score %.0f, RSI: %.1f, %s)\n", r.Symbol, r.SignalType, r.Score, r.CurrentRSI, r.Regime)
 			}
 		}
 	}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==================== MULTI-TIMEFRAME ANALYSIS ====================
+
+// MTFZone is a higher-timeframe S/R zone projected onto the base timeframe
+type MTFZone struct {
+	SRZone
+	Interval string
+}
+
+// ConfluenceResult summarizes how well higher timeframes agree with a signal
+type ConfluenceResult struct {
+	Side    string
+	Score   float64  // 0-100
+	Factors []string // Human-readable reasons that contributed to the score
+	Zone    *MTFZone // Higher-timeframe zone price is currently at (if any)
+}
+
+// MultiTimeframeEngine analyzes one symbol across several intervals in one pass.
+// The lowest interval is the base (execution) timeframe; the others act as filters.
+type MultiTimeframeEngine struct {
+	Symbol    string
+	Intervals []string // Sorted lowest -> highest
+	Limit     int
+	Engines   map[string]*TradingEngine
+
+	attached map[string]bool // Engines owned by the caller (not fetched here)
+}
+
+// NewMultiTimeframeEngine creates one TradingEngine per interval
+func NewMultiTimeframeEngine(symbol string, intervals []string, limit int) *MultiTimeframeEngine {
+	m := &MultiTimeframeEngine{
+		Symbol:   symbol,
+		Limit:    limit,
+		Engines:  make(map[string]*TradingEngine),
+		attached: make(map[string]bool),
+	}
+
+	for _, interval := range intervals {
+		if _, exists := m.Engines[interval]; exists {
+			continue
+		}
+		m.Engines[interval] = NewTradingEngine(symbol, interval, limit)
+		m.Intervals = append(m.Intervals, interval)
+	}
+	m.sortIntervals()
+
+	return m
+}
+
+// AttachBase registers an engine that the caller already fetches and analyzes
+// (e.g. the paper trading engine), so Analyze() does not fetch it twice
+func (m *MultiTimeframeEngine) AttachBase(engine *TradingEngine) {
+	if _, exists := m.Engines[engine.Interval]; !exists {
+		m.Intervals = append(m.Intervals, engine.Interval)
+	}
+	m.Engines[engine.Interval] = engine
+	m.attached[engine.Interval] = true
+	m.sortIntervals()
+}
+
+// sortIntervals orders intervals by candle duration (lowest first)
+func (m *MultiTimeframeEngine) sortIntervals() {
+	for i := 0; i < len(m.Intervals)-1; i++ {
+		for j := i + 1; j < len(m.Intervals); j++ {
+			if m.Engines[m.Intervals[j]].parseCandleDuration() < m.Engines[m.Intervals[i]].parseCandleDuration() {
+				m.Intervals[i], m.Intervals[j] = m.Intervals[j], m.Intervals[i]
+			}
+		}
+	}
+}
+
+// BaseInterval returns the lowest (execution) timeframe
+func (m *MultiTimeframeEngine) BaseInterval() string {
+	if len(m.Intervals) == 0 {
+		return ""
+	}
+	return m.Intervals[0]
+}
+
+// BaseEngine returns the engine for the execution timeframe
+func (m *MultiTimeframeEngine) BaseEngine() *TradingEngine {
+	return m.Engines[m.BaseInterval()]
+}
+
+// HigherIntervals returns every interval above the base timeframe
+func (m *MultiTimeframeEngine) HigherIntervals() []string {
+	if len(m.Intervals) <= 1 {
+		return nil
+	}
+	return m.Intervals[1:]
+}
+
+// Analyze fetches and analyzes every timeframe in parallel
+func (m *MultiTimeframeEngine) Analyze() error {
+	var wg sync.WaitGroup
+	errorsChan := make(chan error, len(m.Intervals))
+	semaphore := make(chan struct{}, NUM_WORKERS)
+
+	for _, interval := range m.Intervals {
+		if m.attached[interval] {
+			continue
+		}
+
+		wg.Add(1)
+		go func(engine *TradingEngine) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if err := engine.FetchData(); err != nil {
+				errorsChan <- fmt.Errorf("%s: %w", engine.Interval, err)
+				return
+			}
+			if len(engine.Candles) == 0 {
+				errorsChan <- fmt.Errorf("%s: no candles returned", engine.Interval)
+				return
+			}
+
+			engine.CalculateIndicators()
+			engine.FindDivergences()
+			engine.IdentifySupportResistance()
+		}(m.Engines[interval])
+	}
+
+	wg.Wait()
+	close(errorsChan)
+
+	for err := range errorsChan {
+		return fmt.Errorf("multi-timeframe analysis failed: %w", err)
+	}
+
+	return nil
+}
+
+// currentPrice returns the latest traded price on the base timeframe
+func (m *MultiTimeframeEngine) currentPrice() float64 {
+	base := m.BaseEngine()
+	if base == nil {
+		return 0
+	}
+	return base.LastPrice()
+}
+
+// ProjectedZones returns all higher-timeframe zones with polarity updated
+// against the base timeframe's current price
+func (m *MultiTimeframeEngine) ProjectedZones() []MTFZone {
+	price := m.currentPrice()
+	var projected []MTFZone
+
+	for _, interval := range m.HigherIntervals() {
+		engine := m.Engines[interval]
+		zones := make([]SRZone, len(engine.SRZones))
+		copy(zones, engine.SRZones)
+		if price > 0 {
+			zones = updateZonePolarityByPrice(zones, price)
+		}
+		for _, zone := range zones {
+			projected = append(projected, MTFZone{SRZone: zone, Interval: interval})
+		}
+	}
+
+	return projected
+}
+
+// CurrentRSI returns the latest RSI for an interval (-1 if unavailable)
+func (m *MultiTimeframeEngine) CurrentRSI(interval string) float64 {
+	engine, exists := m.Engines[interval]
+	if !exists || len(engine.RSI) == 0 {
+		return -1
+	}
+	return engine.RSI[len(engine.RSI)-1]
+}
+
+// RecentDivergences returns the divergences on an interval whose end swing is
+// within MTF_DIVERGENCE_LOOKBACK candles of that interval
+func (m *MultiTimeframeEngine) RecentDivergences(interval string) []BearishDivergence {
+	engine, exists := m.Engines[interval]
+	if !exists {
+		return nil
+	}
+
+	lookback := time.Duration(MTF_DIVERGENCE_LOOKBACK) * engine.parseCandleDuration()
+	var recent []BearishDivergence
+	for _, div := range engine.Divergences {
+		divTime, _ := time.Parse("2006-01-02 15:04", div.EndTime)
+		if time.Since(divTime) < lookback {
+			recent = append(recent, div)
+		}
+	}
+	return recent
+}
+
+// ConfluenceScore scores a base-timeframe signal against the higher timeframes.
+// For a SHORT: price at a HTF resistance, HTF RSI overbought and HTF bearish
+// divergences each add to the score. LONG mirrors the zone and RSI checks.
+func (m *MultiTimeframeEngine) ConfluenceScore(side string) ConfluenceResult {
+	result := ConfluenceResult{Side: side}
+	higher := m.HigherIntervals()
+	price := m.currentPrice()
+	if len(higher) == 0 || price <= 0 {
+		return result
+	}
+
+	const (
+		zoneWeight       = 40.0
+		rsiWeight        = 30.0
+		divergenceWeight = 30.0
+	)
+
+	// Factor 1: Price at a higher-timeframe zone (higher intervals weigh more)
+	zones := m.ProjectedZones()
+	bestZoneScore := 0.0
+	for i := range zones {
+		zone := &zones[i]
+		proximity := MTF_ZONE_PROXIMITY_PERCENT / 100 * price
+		atZone := price >= zone.ZoneBot-proximity && price <= zone.ZoneTop+proximity
+		if !atZone {
+			continue
+		}
+
+		// SHORT wants resistance (price not above the zone), LONG wants support
+		if side == "SHORT" && price > zone.ZoneTop {
+			continue
+		}
+		if side == "LONG" && price < zone.ZoneBot {
+			continue
+		}
+
+		rank := m.intervalRank(zone.Interval)
+		zoneScore := zoneWeight * float64(rank) / float64(len(higher))
+		if zoneScore > bestZoneScore {
+			bestZoneScore = zoneScore
+			result.Zone = zone
+		}
+	}
+	if result.Zone != nil {
+		result.Score += bestZoneScore
+		result.Factors = append(result.Factors, fmt.Sprintf("At %s %s zone $%.4f-$%.4f",
+			result.Zone.Interval, result.Zone.Type, result.Zone.ZoneBot, result.Zone.ZoneTop))
+	}
+
+	// Factor 2: Higher-timeframe RSI extremity
+	for _, interval := range higher {
+		rsi := m.CurrentRSI(interval)
+		if rsi < 0 {
+			continue
+		}
+		share := rsiWeight / float64(len(higher))
+		if side == "SHORT" && rsi > 70 {
+			result.Score += share
+			result.Factors = append(result.Factors, fmt.Sprintf("%s RSI overbought (%.1f)", interval, rsi))
+		} else if side == "SHORT" && rsi > 60 {
+			result.Score += share / 2
+			result.Factors = append(result.Factors, fmt.Sprintf("%s RSI elevated (%.1f)", interval, rsi))
+		} else if side == "LONG" && rsi < 30 {
+			result.Score += share
+			result.Factors = append(result.Factors, fmt.Sprintf("%s RSI oversold (%.1f)", interval, rsi))
+		} else if side == "LONG" && rsi < 40 {
+			result.Score += share / 2
+			result.Factors = append(result.Factors, fmt.Sprintf("%s RSI depressed (%.1f)", interval, rsi))
+		}
+	}
+
+	// Factor 3: Higher-timeframe bearish divergences (SHORT only)
+	if side == "SHORT" {
+		for _, interval := range higher {
+			if divs := m.RecentDivergences(interval); len(divs) > 0 {
+				result.Score += divergenceWeight / float64(len(higher))
+				result.Factors = append(result.Factors, fmt.Sprintf("%s bearish divergence (%d recent)", interval, len(divs)))
+			}
+		}
+	}
+
+	result.Score = math.Min(100, result.Score)
+	return result
+}
+
+// intervalRank returns 1 for the first higher interval, 2 for the next, ...
+func (m *MultiTimeframeEngine) intervalRank(interval string) int {
+	for i, iv := range m.HigherIntervals() {
+		if iv == interval {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// PrintConfluence displays per-timeframe state and the confluence score
+func (m *MultiTimeframeEngine) PrintConfluence(result ConfluenceResult) {
+	fmt.Println("\n════════════════════════════════════════")
+	fmt.Printf("🧭 MULTI-TIMEFRAME CONFLUENCE: %s (%s)\n", m.Symbol, strings.Join(m.Intervals, " / "))
+	fmt.Println("════════════════════════════════════════")
+
+	for _, interval := range m.Intervals {
+		engine := m.Engines[interval]
+		label := "HTF"
+		if interval == m.BaseInterval() {
+			label = "BASE"
+		}
+		fmt.Printf("  [%s] %-4s RSI: %6.2f | Div: %d | S/R: %d\n",
+			label, interval, m.CurrentRSI(interval), len(engine.Divergences), len(engine.SRZones))
+	}
+
+	fmt.Printf("\n🎯 %s Confluence Score: %.0f/100\n", result.Side, result.Score)
+	if len(result.Factors) == 0 {
+		fmt.Println("   No higher-timeframe confirmation")
+	}
+	for _, factor := range result.Factors {
+		fmt.Printf("   ✓ %s\n", factor)
+	}
+	fmt.Println("════════════════════════════════════════")
+}
+
+// RunMultiTimeframeAnalysis runs a one-shot analysis and prints the base
+// timeframe's trade signal alongside the higher-timeframe confluence
+func RunMultiTimeframeAnalysis(symbol string, intervals []string, limit int) error {
+	m := NewMultiTimeframeEngine(symbol, intervals, limit)
+	if len(m.Intervals) < 2 {
+		return fmt.Errorf("multi-timeframe analysis needs at least 2 intervals, got %d", len(m.Intervals))
+	}
+
+	fmt.Printf("\n🧭 Multi-timeframe analysis for %s: %s\n", symbol, strings.Join(m.Intervals, ", "))
+
+	if err := m.Analyze(); err != nil {
+		return err
+	}
+
+	m.BaseEngine().GenerateTradeSignals()
+	m.PrintConfluence(m.ConfluenceScore("SHORT"))
+
+	return nil
+}
+
+// parseIntervalList splits a comma-separated interval flag ("1m,15m,4h")
+func parseIntervalList(list string) []string {
+	var intervals []string
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			intervals = append(intervals, part)
+		}
+	}
+	return intervals
+}
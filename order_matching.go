@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ==================== SIMULATED ORDER MATCHING ====================
+
+// OrderMatcher is implemented by brokers that simulate fills for resting orders
+type OrderMatcher interface {
+	// ProcessCandle matches the symbol's resting orders against a candle and
+	// returns the orders that filled
+	ProcessCandle(symbol string, candle Candle) []Order
+}
+
+// isStopOrder reports whether an order is still waiting for its trigger
+func isStopOrder(order *Order) bool {
+	return order.Type == ORDER_TYPE_STOP_MARKET || (order.Type == ORDER_TYPE_STOP_LIMIT && !order.Triggered)
+}
+
+// stopTriggerPrice returns where a stop triggers within the bar (gaps fill at the open)
+func stopTriggerPrice(order *Order, bar Candle) (float64, bool) {
+	if order.Side == ORDER_SIDE_BUY {
+		if bar.Open >= order.StopPrice {
+			return bar.Open, true
+		}
+		return order.StopPrice, bar.High >= order.StopPrice
+	}
+	if bar.Open <= order.StopPrice {
+		return bar.Open, true
+	}
+	return order.StopPrice, bar.Low <= order.StopPrice
+}
+
+// limitFillPrice returns where a limit fills within the bar (gaps through the
+// limit fill at the better open)
+func limitFillPrice(order *Order, bar Candle) (float64, bool) {
+	if order.Side == ORDER_SIDE_BUY {
+		if bar.Open <= order.Price {
+			return bar.Open, true
+		}
+		return order.Price, bar.Low <= order.Price
+	}
+	if bar.Open >= order.Price {
+		return bar.Open, true
+	}
+	return order.Price, bar.High >= order.Price
+}
+
+// ProcessTick matches resting orders against a single trade price
+func (b *PaperBroker) ProcessTick(symbol string, price float64, t time.Time) []Order {
+	return b.ProcessCandle(symbol, Candle{OpenTime: t, Open: price, High: price, Low: price, Close: price})
+}
+
+// ProcessCandle matches the symbol's resting orders against a candle's range.
+// Stops are matched before limits, so when one bar spans both legs of an OCO
+// the stop wins (the pessimistic assumption without intrabar data). Orders
+// placed after the candle opened only see its close, since the earlier part
+// of the range happened before they existed. Reduce-only orders are capped at
+// the open position and canceled once it's flat; filling one leg of an OCO
+// cancels the rest of its group.
+func (b *PaperBroker) ProcessCandle(symbol string, candle Candle) []Order {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var filled []Order
+	for _, stops := range []bool{true, false} {
+		for _, order := range append([]*Order(nil), b.book...) {
+			if order.Symbol != symbol || order.Status != ORDER_STATUS_NEW || isStopOrder(order) != stops {
+				continue
+			}
+
+			bar := candle
+			if order.CreateTime.After(candle.OpenTime) {
+				bar = Candle{OpenTime: candle.OpenTime, Open: candle.Close, High: candle.Close, Low: candle.Close, Close: candle.Close}
+			}
+
+			var price float64
+			var matched bool
+			switch {
+			case order.Type == ORDER_TYPE_STOP_MARKET:
+				price, matched = stopTriggerPrice(order, bar)
+			case order.Type == ORDER_TYPE_STOP_LIMIT && !order.Triggered:
+				trigger, triggered := stopTriggerPrice(order, bar)
+				if !triggered {
+					continue
+				}
+				// Fill at the trigger if the limit allows it, otherwise rest as a limit
+				order.Triggered = true
+				if (order.Side == ORDER_SIDE_BUY && trigger <= order.Price) ||
+					(order.Side == ORDER_SIDE_SELL && trigger >= order.Price) {
+					price, matched = trigger, true
+				}
+			default:
+				price, matched = limitFillPrice(order, bar)
+			}
+			if !matched {
+				continue
+			}
+
+			quantity := order.Quantity
+			if order.ReduceOnly {
+				quantity = b.reducibleQuantity(order.Symbol, order.Side, quantity)
+			}
+			b.removeOrder(order.ID)
+			if quantity <= 0 {
+				b.cancelled(order) // Nothing left to reduce
+				continue
+			}
+
+			b.fill(order, quantity, price)
+			filled = append(filled, *order)
+			b.cancelOCOGroup(order)
+		}
+	}
+
+	b.cancelStaleReduceOnly(symbol)
+	return filled
+}
+
+// cancelOCOGroup cancels the other resting orders in a filled order's group
+// (caller holds the lock)
+func (b *PaperBroker) cancelOCOGroup(filled *Order) {
+	if filled.OCOGroup == "" {
+		return
+	}
+	for _, order := range append([]*Order(nil), b.book...) {
+		if order.OCOGroup == filled.OCOGroup && order.ID != filled.ID {
+			b.removeOrder(order.ID)
+			b.cancelled(order)
+		}
+	}
+}
+
+// cancelStaleReduceOnly cancels reduce-only orders left on a flat symbol
+// (caller holds the lock)
+func (b *PaperBroker) cancelStaleReduceOnly(symbol string) {
+	if pos, exists := b.positions[symbol]; exists && math.Abs(pos.Quantity) > 0 {
+		return
+	}
+	for _, order := range append([]*Order(nil), b.book...) {
+		if order.Symbol == symbol && order.ReduceOnly {
+			b.removeOrder(order.ID)
+			b.cancelled(order)
+		}
+	}
+}
+
+// ==================== LIMIT ENTRIES ====================
+
+// Entry order modes (--entry-order)
+const (
+	ENTRY_ORDER_MARKET = "market" // Enter at the last price
+	ENTRY_ORDER_LIMIT  = "limit"  // Rest a limit at the nearest zone edge
+)
+
+// Active entry order mode (set from flags)
+var ENTRY_ORDER_TYPE = ENTRY_ORDER_MARKET
+
+// PendingEntry is a resting limit entry that becomes a trade when it fills
+type PendingEntry struct {
+	Symbol       string
+	Side         string
+	OrderID      string
+	LimitPrice   float64
+	Quantity     float64
+	StopLoss     float64
+	TakeProfit   float64
+	TPMethod     string
+	Score        float64
+	DivergenceID string
+	ScaleIn      *ScaleInRule
+	Replaces     *Replacement // Open trade to close when this entry fills
+	PlacedAt     time.Time
+	ExpiresAt    time.Time
+}
+
+// PlaceLimitEntry rests a limit entry at the candidate's zone edge. It holds a
+// slot until it fills (see matchOrders) or expires after LIMIT_ENTRY_EXPIRY_CANDLES.
+// The trade it replaces (may be nil) is closed when it fills.
+func (mp *MultiPaperTradingEngine) PlaceLimitEntry(c SignalCandidate, size float64, replace *Replacement) bool {
+	side := "SHORT"
+	rounded, err := EXCHANGE_FILTERS.Get(c.Symbol).RoundOrder(side, c.ZoneEdge, c.StopLoss, c.TakeProfit, size)
+	if err != nil {
+		fmt.Printf("   ⛔ [%s] Order rejected: %v\n", c.Symbol, err)
+		return false
+	}
+
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	if !mp.canEnter(c.Symbol, replace) {
+		return false
+	}
+
+	order, err := mp.Broker.PlaceOrder(OrderRequest{
+		Symbol:   c.Symbol,
+		Side:     entryOrderSide(side),
+		Type:     ORDER_TYPE_LIMIT,
+		Quantity: rounded.Quantity,
+		Price:    rounded.Entry,
+	})
+	if err != nil {
+		fmt.Printf("   ⛔ [%s] Limit entry failed: %v\n", c.Symbol, err)
+		return false
+	}
+
+	expiry := time.Duration(LIMIT_ENTRY_EXPIRY_CANDLES) * time.Minute
+	if candle, err := parseIntervalDuration(mp.Interval); err == nil {
+		expiry = candle * time.Duration(LIMIT_ENTRY_EXPIRY_CANDLES)
+	}
+
+	now := time.Now()
+	mp.PendingEntries[c.Symbol] = &PendingEntry{
+		Symbol:       c.Symbol,
+		Side:         side,
+		OrderID:      order.ID,
+		LimitPrice:   rounded.Entry,
+		Quantity:     rounded.Quantity,
+		StopLoss:     rounded.StopLoss,
+		TakeProfit:   rounded.TakeProfit,
+		TPMethod:     c.TPMethod,
+		Score:        c.Score.Total,
+		DivergenceID: c.DivergenceID,
+		ScaleIn:      NewScaleInRule(c.ZoneBot, c.ZoneTop),
+		Replaces:     replace,
+		PlacedAt:     now,
+		ExpiresAt:    now.Add(expiry),
+	}
+
+	fmt.Printf("\n📌 [%s] %s LIMIT @ $%.4f (zone edge, +%.2f%%) | SL: $%.4f | TP: $%.4f | expires %s\n",
+		c.Symbol, side, rounded.Entry, (rounded.Entry-c.Entry)/c.Entry*100,
+		rounded.StopLoss, rounded.TakeProfit, now.Add(expiry).UTC().Format("15:04 UTC"))
+	if replace != nil {
+		fmt.Printf("   🔄 [%s] Replaces %s (score %.0f) when it fills\n", c.Symbol, replace.Symbol, replace.Score)
+	}
+	return true
+}
+
+// openExposure returns the open positions plus resting limit entries (as
+// trades at their limit notional) and their total notional, leaving out
+// exclude. Used by the sizing and exposure caps. (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) openExposure(exclude string) (float64, []*PaperTrade) {
+	notional := 0.0
+	var open []*PaperTrade
+	for symbol, trade := range mp.ActiveTrades {
+		if symbol != exclude {
+			notional += trade.Size
+			open = append(open, trade)
+		}
+	}
+	for symbol, pending := range mp.PendingEntries {
+		if symbol != exclude {
+			size := pending.Quantity * pending.LimitPrice
+			notional += size
+			open = append(open, &PaperTrade{Symbol: symbol, Side: pending.Side, Size: size})
+		}
+	}
+	return notional, open
+}
+
+// matchOrders feeds each symbol's latest candle to the broker's matching engine
+// and books the fills: entries open trades, stops and targets close them
+// (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) matchOrders(matcher OrderMatcher, candles map[string][]Candle) {
+	symbols := make(map[string]bool)
+	for symbol := range mp.ActiveTrades {
+		symbols[symbol] = true
+	}
+	for symbol := range mp.PendingEntries {
+		symbols[symbol] = true
+	}
+
+	for symbol := range symbols {
+		recent := candles[symbol]
+		if len(recent) == 0 {
+			continue
+		}
+		for _, order := range matcher.ProcessCandle(symbol, recent[len(recent)-1]) {
+			mp.applyFill(order, candles)
+		}
+	}
+}
+
+// applyFill books one filled order (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) applyFill(order Order, candles map[string][]Candle) {
+	if pending, exists := mp.PendingEntries[order.Symbol]; exists && pending.OrderID == order.ID {
+		delete(mp.PendingEntries, order.Symbol)
+		fmt.Printf("\n✅ [%s] Limit entry filled @ $%.4f\n", order.Symbol, order.AvgPrice)
+		trade := mp.registerTrade(order.Symbol, pending.Side, order.AvgPrice, order.ExecutedQty,
+			pending.StopLoss, pending.TakeProfit, pending.TPMethod, pending.Score)
+		trade.DivergenceID = pending.DivergenceID
+		trade.ScaleIn = pending.ScaleIn
+
+		if replace := pending.Replaces; replace != nil {
+			if recent := candles[replace.Symbol]; len(recent) > 0 {
+				replace.Price = recent[len(recent)-1].Close
+			}
+			mp.closeReplaced(replace, order.Symbol, pending.Score)
+		}
+		return
+	}
+
+	trade, exists := mp.ActiveTrades[order.Symbol]
+	if !exists {
+		return
+	}
+
+	var reason string
+	switch order.ID {
+	case trade.StopOrderID:
+		reason = "STOP_LOSS"
+	case trade.TakeProfitOrderID:
+		reason = "TAKE_PROFIT"
+	default:
+		return
+	}
+
+	// The broker canceled the other OCO leg with the fill
+	trade.StopOrderID, trade.TakeProfitOrderID = "", ""
+	mp.recordClose(order.Symbol, order.AvgPrice, reason)
+}
+
+// expirePendingEntries cancels limit entries past their expiry (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) expirePendingEntries() {
+	now := time.Now()
+	for symbol, pending := range mp.PendingEntries {
+		if now.Before(pending.ExpiresAt) {
+			continue
+		}
+		if err := mp.Broker.CancelOrder(symbol, pending.OrderID); err != nil && VERBOSE_MODE {
+			fmt.Printf("⚠️  [%s] Cancel limit entry %s: %v\n", symbol, pending.OrderID, err)
+		}
+		delete(mp.PendingEntries, symbol)
+		fmt.Printf("   ⌛ [%s] Limit entry @ $%.4f expired unfilled\n", symbol, pending.LimitPrice)
+	}
+}
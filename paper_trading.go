@@ -8,21 +8,32 @@ import (
 )
 
 type PaperTrade struct {
-	ID            int
-	Symbol        string
-	Interval      string
-	Side          string
-	EntryPrice    float64
-	EntryTime     time.Time
-	StopLoss      float64
-	TakeProfit    float64
-	Size          float64
-	Status        string
-	ExitPrice     float64
-	ExitTime      time.Time
-	ProfitLoss    float64
-	ProfitLossPct float64
-	RiskReward    float64
+	ID                int
+	Symbol            string
+	Interval          string
+	Side              string
+	EntryPrice        float64
+	EntryTime         time.Time
+	StopLoss          float64
+	TakeProfit        float64
+	TPMethod          string  // How the take-profit was chosen (sr_zone, fib_*, fixed_percent)
+	SignalScore       float64 // 0-100 confidence score at entry
+	DivergenceID      string  // Divergence that produced the entry (see BearishDivergence.ID)
+	StopOrderID       string  // Broker stop order protecting the position
+	TakeProfitOrderID string  // Broker target order (OCO with the stop)
+	Size              float64
+	Quantity          float64      // Base asset quantity after lot-size rounding
+	EntrySlippagePct  float64      // Entry fill vs signal price, positive = adverse
+	ExitSlippagePct   float64      // Exit fill vs trigger price, positive = adverse
+	Fills             []TradeFill  // Entry legs; EntryPrice is their weighted average
+	ScaleIn           *ScaleInRule // When to add to the position (nil = never)
+	PartialExits      []TradeExit  // Exit ladder steps taken before the final exit
+	Status            string
+	ExitPrice         float64
+	ExitTime          time.Time
+	ProfitLoss        float64
+	ProfitLossPct     float64
+	RiskReward        float64
 
 	// Track price extremes during trade
 	HighestPrice float64 // Highest price reached during trade
@@ -33,6 +44,23 @@ type PaperTrade struct {
 	MaxProfitPct float64 // Maximum profit percentage
 }
 
+// TradeExit is one partial exit of a position
+type TradeExit struct {
+	Time     time.Time
+	Price    float64
+	Quantity float64
+	Profit   float64
+}
+
+// NetProfitLoss returns the final exit's P/L plus the profit banked by partial exits
+func (t *PaperTrade) NetProfitLoss() float64 {
+	net := t.ProfitLoss
+	for _, exit := range t.PartialExits {
+		net += exit.Profit
+	}
+	return net
+}
+
 type PaperTradingEngine struct {
 	*TradingEngine
 	StartingBalance float64
@@ -45,6 +73,9 @@ type PaperTradingEngine struct {
 	TotalProfit     float64
 	TotalLoss       float64
 	Logger          *TradeLogger
+	MTF             *MultiTimeframeEngine // Optional higher-timeframe confluence filter
+	Sizer           PositionSizer         // Position sizing model (--sizing)
+	Broker          Broker                // Executes orders (--broker)
 }
 
 func NewPaperTradingEngine(symbol, interval string, limit int, startingBalance float64) *PaperTradingEngine {
@@ -55,6 +86,14 @@ func NewPaperTradingEngine(symbol, interval string, limit int, startingBalance f
 		logger = nil
 	}
 
+	// The broker's account balance is the starting point, not --balance
+	broker := newConfiguredBroker(startingBalance)
+	if balance, err := quoteBalance(broker); err != nil {
+		fmt.Printf("⚠️  Broker balance unavailable (%v) - starting from $%.2f\n", err, startingBalance)
+	} else {
+		startingBalance = balance
+	}
+
 	return &PaperTradingEngine{
 		TradingEngine:   NewTradingEngine(symbol, interval, limit),
 		StartingBalance: startingBalance,
@@ -62,13 +101,60 @@ func NewPaperTradingEngine(symbol, interval string, limit int, startingBalance f
 		Trades:          make([]PaperTrade, 0),
 		TradeCounter:    0,
 		Logger:          logger,
+		Sizer:           newConfiguredSizer(),
+		Broker:          broker,
 	}
 }
 
-func (p *PaperTradingEngine) OpenTrade(side string, entryPrice, stopLoss, takeProfit, size float64) {
+// syncBalance takes the balance from the broker, keeping the last known
+// balance if it can't be read
+func (p *PaperTradingEngine) syncBalance() {
+	balance, err := quoteBalance(p.Broker)
+	if err != nil {
+		fmt.Printf("⚠️  Balance sync failed: %v - keeping $%.2f\n", err, p.CurrentBalance)
+		return
+	}
+	p.CurrentBalance = balance
+}
+
+// EnableMultiTimeframe adds higher-timeframe confluence as an entry filter
+func (p *PaperTradingEngine) EnableMultiTimeframe(intervals []string) {
+	p.MTF = NewMultiTimeframeEngine(p.Symbol, intervals, p.Limit)
+	p.MTF.AttachBase(p.TradingEngine)
+}
+
+// OpenTrade opens a position through the broker, rounded to the exchange
+// filters. Returns false if the trade was not opened.
+func (p *PaperTradingEngine) OpenTrade(side string, entryPrice, stopLoss, takeProfit float64, tpMethod string, score, size float64) bool {
 	if p.ActiveTrade != nil {
 		fmt.Println("⚠️  Already have an open trade. Close it first.")
-		return
+		return false
+	}
+
+	// Round to tick/lot size and reject what the exchange would refuse
+	order, err := EXCHANGE_FILTERS.Get(p.Symbol).RoundOrder(side, entryPrice, stopLoss, takeProfit, size)
+	if err != nil {
+		fmt.Printf("\n⛔ [%s] Order rejected: %v\n", p.Symbol, err)
+		return false
+	}
+	entryPrice, stopLoss, takeProfit, size = order.Entry, order.StopLoss, order.TakeProfit, order.Notional
+
+	// Execute the entry through the broker
+	entry, err := p.Broker.PlaceOrder(OrderRequest{
+		Symbol:   p.Symbol,
+		Side:     entryOrderSide(side),
+		Type:     ORDER_TYPE_MARKET,
+		Quantity: order.Quantity,
+		Price:    entryPrice,
+	})
+	if err != nil {
+		fmt.Printf("\n⛔ [%s] Entry order failed: %v\n", p.Symbol, err)
+		return false
+	}
+	quantity := order.Quantity
+	if entry.ExecutedQty > 0 && entry.AvgPrice > 0 {
+		entryPrice, quantity = entry.AvgPrice, entry.ExecutedQty
+		size = quantity * entryPrice
 	}
 
 	p.TradeCounter++
@@ -81,13 +167,22 @@ func (p *PaperTradingEngine) OpenTrade(side string, entryPrice, stopLoss, takePr
 		EntryTime:    time.Now(),
 		StopLoss:     stopLoss,
 		TakeProfit:   takeProfit,
+		TPMethod:     tpMethod,
+		SignalScore:  score,
 		Size:         size,
+		Quantity:     quantity,
+		Fills:        []TradeFill{{Time: time.Now(), Price: entryPrice, Quantity: quantity}},
 		Status:       "OPEN",
 		HighestPrice: entryPrice, // Initialize to entry price
 		LowestPrice:  entryPrice, // Initialize to entry price
 		MaxProfit:    0,
 		MaxProfitPct: 0,
 	}
+	trade.EntrySlippagePct = slippagePercent(entry.Side, order.Entry, entryPrice)
+	if trade.EntrySlippagePct != 0 {
+		fmt.Printf("   💧 [%s] Entry slippage: %+.3f%% ($%.4f -> $%.4f)\n",
+			p.Symbol, trade.EntrySlippagePct, order.Entry, entryPrice)
+	}
 
 	risk := 0.0
 	if side == "SHORT" {
@@ -107,6 +202,7 @@ func (p *PaperTradingEngine) OpenTrade(side string, entryPrice, stopLoss, takePr
 		trade.RiskReward = reward / risk
 	}
 
+	placeExitOrders(p.Broker, &trade)
 	p.ActiveTrade = &trade
 
 	if VERBOSE_MODE {
@@ -116,17 +212,23 @@ func (p *PaperTradingEngine) OpenTrade(side string, entryPrice, stopLoss, takePr
 		fmt.Printf("\n📝 Trade #%d: %s %s\n", trade.ID, side, p.Symbol)
 		fmt.Printf("💰 Entry:       $%.2f\n", entryPrice)
 		fmt.Printf("🛑 Stop Loss:   $%.2f (%.2f%%)\n", stopLoss, (risk/entryPrice)*100)
-		fmt.Printf("🎯 Take Profit: $%.2f (%.2f%%)\n", takeProfit, (reward/entryPrice)*100)
+		fmt.Printf("🎯 Take Profit: $%.2f (%.2f%%, %s)\n", takeProfit, (reward/entryPrice)*100, tpMethod)
 		fmt.Printf("📊 Size:        $%.2f\n", size)
 		fmt.Printf("⚖️  Risk/Reward: %.2f:1\n", trade.RiskReward)
+		fmt.Printf("🏅 Score:       %.0f/100\n", score)
 		fmt.Printf("⏰ Time:        %s\n", trade.EntryTime.Format("2006-01-02 15:04:05"))
 		fmt.Println("════════════════════════════════════════")
 	} else {
 		fmt.Printf("\n🎯 [%s] %s OPENED @ $%.2f | SL: $%.2f | TP: $%.2f | Size: $%.2f\n",
 			p.Symbol, side, entryPrice, stopLoss, takeProfit, size)
 	}
+	return true
 }
 
+// CheckAndClosePosition tracks the active trade at the current price and
+// closes it at its stop or target. The stop and target rest with the broker
+// as a reduce-only OCO pair; a simulated broker fills them from the latest
+// kline, other brokers fall back to comparing prices.
 func (p *PaperTradingEngine) CheckAndClosePosition(currentPrice float64) {
 	if p.ActiveTrade == nil {
 		return
@@ -158,6 +260,14 @@ func (p *PaperTradingEngine) CheckAndClosePosition(currentPrice float64) {
 		trade.MaxProfitPct = currentProfitPct
 	}
 
+	matcher, simulated := p.Broker.(OrderMatcher)
+	if simulated && len(p.RawCandles) > 0 {
+		p.matchExitOrders(matcher, p.RawCandles[len(p.RawCandles)-1])
+	}
+	if p.ActiveTrade == nil || (simulated && trade.StopOrderID != "" && trade.TakeProfitOrderID != "") {
+		return
+	}
+
 	shouldClose := false
 	closeReason := ""
 
@@ -184,12 +294,72 @@ func (p *PaperTradingEngine) CheckAndClosePosition(currentPrice float64) {
 	}
 }
 
+// matchExitOrders feeds a kline to the broker's matching engine and books the
+// trade if its stop or target filled
+func (p *PaperTradingEngine) matchExitOrders(matcher OrderMatcher, candle Candle) {
+	trade := p.ActiveTrade
+	for _, order := range matcher.ProcessCandle(p.Symbol, candle) {
+		var reason string
+		switch order.ID {
+		case trade.StopOrderID:
+			reason = "STOP_LOSS"
+		case trade.TakeProfitOrderID:
+			reason = "TAKE_PROFIT"
+		default:
+			continue
+		}
+
+		// The broker canceled the other OCO leg with the fill
+		trade.StopOrderID, trade.TakeProfitOrderID = "", ""
+		p.recordClose(order.AvgPrice, reason)
+		return
+	}
+}
+
+// CloseTrade exits the active trade through the broker at market and books it
 func (p *PaperTradingEngine) CloseTrade(exitPrice float64, reason string) {
 	if p.ActiveTrade == nil {
 		return
 	}
 
+	// Cancel the stop and target orders and exit through the broker
 	trade := p.ActiveTrade
+	cancelExitOrders(p.Broker, trade)
+	exit, err := p.Broker.PlaceOrder(OrderRequest{
+		Symbol:     p.Symbol,
+		Side:       exitOrderSide(trade.Side),
+		Type:       ORDER_TYPE_MARKET,
+		Quantity:   trade.Quantity,
+		Price:      exitPrice,
+		ReduceOnly: true,
+	})
+	if err != nil {
+		if brokerHasPosition(p.Broker, p.Symbol) {
+			fmt.Printf("⚠️  [%s] Exit order failed: %v - position stays open\n", p.Symbol, err)
+			placeExitOrders(p.Broker, trade)
+			return
+		}
+		// Already flat at the broker - book it at the last price
+		fmt.Printf("⚠️  [%s] Exit order failed (%v) but the broker shows no position - closing\n", p.Symbol, err)
+	} else if exit.AvgPrice > 0 {
+		trade.ExitSlippagePct = slippagePercent(exit.Side, exitPrice, exit.AvgPrice)
+		if trade.ExitSlippagePct != 0 {
+			fmt.Printf("   💧 [%s] Exit slippage: %+.3f%% ($%.4f -> $%.4f)\n",
+				p.Symbol, trade.ExitSlippagePct, exitPrice, exit.AvgPrice)
+		}
+		exitPrice = exit.AvgPrice
+	}
+
+	p.recordClose(exitPrice, reason)
+}
+
+// recordClose books the active trade whose exit has already executed
+func (p *PaperTradingEngine) recordClose(exitPrice float64, reason string) {
+	trade := p.ActiveTrade
+	if trade == nil {
+		return
+	}
+
 	trade.ExitPrice = exitPrice
 	trade.ExitTime = time.Now()
 
@@ -221,7 +391,7 @@ func (p *PaperTradingEngine) CloseTrade(exitPrice float64, reason string) {
 		}
 	}
 
-	p.CurrentBalance += trade.ProfitLoss
+	p.syncBalance()
 	p.Trades = append(p.Trades, *trade)
 
 	// Log trade to CSV
@@ -417,10 +587,11 @@ func (p *PaperTradingEngine) RunPaperTrading() error {
 		p.FindDivergences()
 		p.IdentifySupportResistance()
 
-		currentPrice := p.Candles[len(p.Candles)-1].Close
+		currentPrice := p.LastPrice()
 		currentRSI := p.RSI[len(p.RSI)-1]
 
-		// Show current portfolio status
+		// Show current portfolio status (balance from the broker)
+		p.syncBalance()
 		totalPL := p.CurrentBalance - p.StartingBalance
 		totalPLPct := (totalPL / p.StartingBalance) * 100
 		fmt.Println("\n┌────────────────────────────────────────┐")
@@ -437,82 +608,70 @@ func (p *PaperTradingEngine) RunPaperTrading() error {
 			p.CheckAndClosePosition(currentPrice)
 		}
 
-		if p.ActiveTrade == nil {
-			recentDivergences := 0
-			for _, div := range p.Divergences {
-				divTime, _ := time.Parse("2006-01-02 15:04", div.EndTime)
-				hoursSince := time.Since(divTime).Hours()
-				if hoursSince < 72 {
-					recentDivergences++
-				}
-			}
+		if p.ActiveTrade == nil && sessionAllowsEntries(time.Now()) {
+			recentDivergences, _, _ := recentDivergenceStats(p.Divergences, SIGNAL_SCORE_CONFIG.RecencyHours)
 
-			if recentDivergences >= MIN_DIVERGENCES_FOR_SIGNAL && currentRSI > 70 {
-				var nearestResistance *SRZone
-				minDistanceUp := 1000000.0
-				for i := range p.SRZones {
-					if p.SRZones[i].Level > currentPrice {
-						distance := p.SRZones[i].Level - currentPrice
-						if distance < minDistanceUp {
-							minDistanceUp = distance
-							nearestResistance = &p.SRZones[i]
-						}
-					}
-				}
-
-				var nearestSupport *SRZone
-				minDistanceDown := 1000000.0
-				for i := range p.SRZones {
-					if p.SRZones[i].Level < currentPrice {
-						distance := currentPrice - p.SRZones[i].Level
-						if distance < minDistanceDown {
-							minDistanceDown = distance
-							nearestSupport = &p.SRZones[i]
-						}
-					}
-				}
+			if recentDivergences >= MIN_DIVERGENCES_FOR_SIGNAL && currentRSI > 70 && p.regimeAllows(STRATEGY_DIVERGENCE_FADE) {
+				nearestResistance, nearestSupport := p.nearestZones(currentPrice)
 
 				entry := currentPrice
-				var stopLoss, takeProfit float64
-
-				if nearestResistance != nil {
-					stopLoss = nearestResistance.ZoneTop
-				} else {
-					stopLoss = currentPrice * (1 + STOP_LOSS_PERCENT/100)
-				}
+				stopLoss, _ := p.selectStopLoss("SHORT", entry, nearestResistance)
 
-				if nearestSupport != nil {
-					takeProfit = nearestSupport.ZoneBot
-				} else {
-					takeProfit = currentPrice * (1 - TAKE_PROFIT_PERCENT/100)
-				}
+				takeProfit, tpMethod := p.selectTakeProfit("SHORT", entry, stopLoss, nearestSupport)
 
 				risk := stopLoss - entry
 				reward := entry - takeProfit
 				rr := reward / risk
+				score := p.ScoreShortSignal(rr, nearestResistance)
+
+				mtfPassed := true
+				if p.MTF != nil && rr >= RISK_REWARD_RATIO {
+					if err := p.MTF.Analyze(); err != nil {
+						fmt.Printf("⚠️  Multi-timeframe analysis failed: %v\n", err)
+						mtfPassed = false
+					} else {
+						confluence := p.MTF.ConfluenceScore("SHORT")
+						p.MTF.PrintConfluence(confluence)
+						if confluence.Score < MIN_MTF_CONFLUENCE_SCORE {
+							fmt.Printf("\n⚠️  Signal skipped: confluence %.0f below minimum %.0f\n",
+								confluence.Score, MIN_MTF_CONFLUENCE_SCORE)
+							mtfPassed = false
+						}
+					}
+				}
 
-				if rr >= RISK_REWARD_RATIO {
-					// ✅ FIXED: Use full balance for single symbol trading
-					// (In single symbol mode, we only trade one pair at a time)
-					positionSize := p.StartingBalance
+				if rr >= RISK_REWARD_RATIO && !score.MeetsMinimum() {
+					fmt.Printf("\n⚠️  Signal skipped: score %.0f below minimum %.0f\n", score.Total, MIN_SIGNAL_SCORE)
+				} else if rr >= RISK_REWARD_RATIO && mtfPassed && p.confirmShortEntry() {
+					// Single symbol mode trades one pair at a time (fixed = full balance),
+					// scaled down for lower-confidence signals
+					positionSize, sizingNote := SizePosition(p.Sizer, SizingInput{
+						StartingBalance: p.StartingBalance,
+						Equity:          p.CurrentBalance,
+						Slots:           1,
+						Entry:           entry,
+						StopLoss:        stopLoss,
+						ATR:             lastValue(p.ATR),
+						ScoreFactor:     score.SizeFactor(),
+						Stats:           rollingTradeStats(p.Trades, KELLY_LOOKBACK),
+					}, sizingLimitsFor(p.Symbol))
+
+					if positionSize <= 0 {
+						fmt.Printf("\n⚠️  Signal skipped: %s\n", sizingNote)
+					} else {
+						if sizingNote != "" && VERBOSE_MODE {
+							fmt.Printf("   ⚠️  %s\n", sizingNote)
+						}
 
-					// Optional: Log risk-based calculation for comparison
-					riskAmount := p.CurrentBalance * (MAX_RISK_PERCENT / 100)
-					riskPercentPrice := (risk / entry) * 100
-					riskBasedSize := riskAmount / (riskPercentPrice / 100)
+						fmt.Println("\n🎯 BEARISH SIGNAL DETECTED!")
+						fmt.Printf("📊 RSI: %.2f (Overbought)\n", currentRSI)
+						fmt.Printf("📈 Divergences: %d\n", recentDivergences)
+						fmt.Printf("⚖️  R/R Ratio: %.2f:1 ✅\n", rr)
+						fmt.Printf("🏅 Score: %.0f/100 (%s, size x%.2f)\n", score.Total, score.Label(), score.SizeFactor())
 
-					if riskBasedSize > positionSize && VERBOSE_MODE {
-						fmt.Printf("   ⚠️  Risk-based size $%.0f capped to $%.0f (1x leverage)\n",
-							riskBasedSize, positionSize)
+						p.OpenTrade("SHORT", entry, stopLoss, takeProfit, tpMethod, score.Total, positionSize)
 					}
-
-					fmt.Println("\n🎯 BEARISH SIGNAL DETECTED!")
-					fmt.Printf("📊 RSI: %.2f (Overbought)\n", currentRSI)
-					fmt.Printf("📈 Divergences: %d\n", recentDivergences)
-					fmt.Printf("⚖️  R/R Ratio: %.2f:1 ✅\n", rr)
-
-					p.OpenTrade("SHORT", entry, stopLoss, takeProfit, positionSize)
-				} else {
+				} else if rr < RISK_REWARD_RATIO {
 					fmt.Println("\n⚠️  Signal detected but R/R ratio too low")
 					fmt.Printf("   R/R: %.2f:1 (min: %.1f:1)\n", rr, RISK_REWARD_RATIO)
 				}
@@ -539,6 +698,7 @@ func (p *PaperTradingEngine) RunPaperTrading() error {
 			fmt.Printf("   Distance to TP: %.2f%%\n", tpDistance)
 		}
 
+		drainBrokerEvents(p.Broker)
 		p.PrintStats()
 
 		if !ENABLE_LIVE_MODE {
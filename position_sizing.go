@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ==================== POSITION SIZING ====================
+
+// Sizing modes (--sizing)
+const (
+	SIZING_FIXED       = "fixed"    // Equal share of the starting balance per slot
+	SIZING_RISK        = "risk"     // Risk MAX_RISK_PERCENT of equity between entry and stop
+	SIZING_ATR         = "atr"      // Risk MAX_RISK_PERCENT of equity over SIZING_ATR_MULTIPLE ATRs
+	SIZING_KELLY       = "kelly"    // Fractional Kelly from rolling trade stats
+	SIZING_COMPOUNDING = "compound" // Equal share of current equity per slot
+)
+
+// Active sizing mode (set from flags)
+var SIZING_MODE = SIZING_FIXED
+
+// TradeStats summarizes recent closed trades for the Kelly sizer
+type TradeStats struct {
+	Count   int
+	WinRate float64 // 0-1
+	AvgWin  float64 // Average winning P/L %
+	AvgLoss float64 // Average losing P/L % (positive)
+}
+
+// rollingTradeStats computes stats over the last `lookback` closed trades
+func rollingTradeStats(trades []PaperTrade, lookback int) TradeStats {
+	start := len(trades) - lookback
+	if start < 0 {
+		start = 0
+	}
+
+	var stats TradeStats
+	wins, losses := 0, 0
+	for _, t := range trades[start:] {
+		stats.Count++
+		if t.ProfitLoss > 0 {
+			wins++
+			stats.AvgWin += t.ProfitLossPct
+		} else {
+			losses++
+			stats.AvgLoss += -t.ProfitLossPct
+		}
+	}
+	if stats.Count > 0 {
+		stats.WinRate = float64(wins) / float64(stats.Count)
+	}
+	if wins > 0 {
+		stats.AvgWin /= float64(wins)
+	}
+	if losses > 0 {
+		stats.AvgLoss /= float64(losses)
+	}
+	return stats
+}
+
+// SizingInput is everything a sizer may need to size one trade
+type SizingInput struct {
+	StartingBalance float64
+	Equity          float64 // Current balance plus unrealized P/L
+	OpenNotional    float64 // Notional already deployed in open positions
+	Slots           int     // Maximum simultaneous positions (1 in single-symbol mode)
+	Entry           float64
+	StopLoss        float64
+	ATR             float64 // Current ATR in price units
+	ScoreFactor     float64 // Signal score multiplier (1 = unscaled)
+	Stats           TradeStats
+}
+
+// riskPerUnitPercent returns the entry-to-stop distance as a % of entry
+func (in SizingInput) riskPerUnitPercent() float64 {
+	if in.Entry <= 0 {
+		return 0
+	}
+	return math.Abs(in.StopLoss-in.Entry) / in.Entry * 100
+}
+
+// PositionSizer returns the notional (quote currency) to open for a trade
+type PositionSizer interface {
+	Name() string
+	Size(in SizingInput) float64
+}
+
+// FixedNotionalSizer gives every slot an equal share of the starting balance
+type FixedNotionalSizer struct{}
+
+func (FixedNotionalSizer) Name() string { return SIZING_FIXED }
+
+func (FixedNotionalSizer) Size(in SizingInput) float64 {
+	return in.StartingBalance / float64(max(in.Slots, 1))
+}
+
+// FixedFractionalSizer risks a fixed % of equity between entry and stop
+type FixedFractionalSizer struct {
+	RiskPercent float64
+}
+
+func (s FixedFractionalSizer) Name() string { return SIZING_RISK }
+
+func (s FixedFractionalSizer) Size(in SizingInput) float64 {
+	stopPercent := in.riskPerUnitPercent()
+	if stopPercent <= 0 {
+		return 0
+	}
+	riskAmount := in.Equity * s.RiskPercent / 100
+	return riskAmount / (stopPercent / 100)
+}
+
+// ATRVolatilitySizer risks a fixed % of equity over an ATR-based move, so
+// positions shrink as volatility rises regardless of where the stop sits
+type ATRVolatilitySizer struct {
+	RiskPercent float64
+	ATRMultiple float64
+}
+
+func (s ATRVolatilitySizer) Name() string { return SIZING_ATR }
+
+func (s ATRVolatilitySizer) Size(in SizingInput) float64 {
+	if in.ATR <= 0 || in.Entry <= 0 {
+		return FixedFractionalSizer{RiskPercent: s.RiskPercent}.Size(in)
+	}
+	movePercent := in.ATR * s.ATRMultiple / in.Entry * 100
+	riskAmount := in.Equity * s.RiskPercent / 100
+	return riskAmount / (movePercent / 100)
+}
+
+// KellySizer risks a fraction of the Kelly-optimal % of equity, estimated from
+// rolling trade stats. Falls back to fixed-fractional risk until MinTrades close.
+type KellySizer struct {
+	Fraction    float64 // Portion of full Kelly to use (e.g., 0.5 = half Kelly)
+	MinTrades   int
+	RiskPercent float64 // Fallback risk % before enough trades
+}
+
+func (s KellySizer) Name() string { return SIZING_KELLY }
+
+func (s KellySizer) Size(in SizingInput) float64 {
+	fallback := FixedFractionalSizer{RiskPercent: s.RiskPercent}
+	if in.Stats.Count < s.MinTrades || in.Stats.AvgLoss <= 0 {
+		return fallback.Size(in)
+	}
+
+	// f* = W - (1 - W) / R, with R = average win / average loss
+	payoff := in.Stats.AvgWin / in.Stats.AvgLoss
+	kelly := in.Stats.WinRate - (1-in.Stats.WinRate)/payoff
+	if kelly <= 0 {
+		return 0 // No edge - sit out
+	}
+	return FixedFractionalSizer{RiskPercent: kelly * s.Fraction * 100}.Size(in)
+}
+
+// CompoundingSizer gives every slot an equal share of current equity
+type CompoundingSizer struct{}
+
+func (CompoundingSizer) Name() string { return SIZING_COMPOUNDING }
+
+func (CompoundingSizer) Size(in SizingInput) float64 {
+	return in.Equity / float64(max(in.Slots, 1))
+}
+
+// NewPositionSizer returns the sizer for a --sizing mode
+func NewPositionSizer(mode string) (PositionSizer, error) {
+	switch strings.ToLower(mode) {
+	case SIZING_FIXED, "":
+		return FixedNotionalSizer{}, nil
+	case SIZING_RISK:
+		return FixedFractionalSizer{RiskPercent: MAX_RISK_PERCENT}, nil
+	case SIZING_ATR:
+		return ATRVolatilitySizer{RiskPercent: MAX_RISK_PERCENT, ATRMultiple: SIZING_ATR_MULTIPLE}, nil
+	case SIZING_KELLY:
+		return KellySizer{Fraction: KELLY_FRACTION, MinTrades: KELLY_MIN_TRADES, RiskPercent: MAX_RISK_PERCENT}, nil
+	case SIZING_COMPOUNDING:
+		return CompoundingSizer{}, nil
+	}
+	return nil, fmt.Errorf("unknown sizing mode %q (use fixed, risk, atr, kelly or compound)", mode)
+}
+
+// newConfiguredSizer returns the sizer for SIZING_MODE, falling back to fixed
+func newConfiguredSizer() PositionSizer {
+	sizer, err := NewPositionSizer(SIZING_MODE)
+	if err != nil {
+		fmt.Printf("⚠️  %v - using %s sizing\n", err, SIZING_FIXED)
+		return FixedNotionalSizer{}
+	}
+	return sizer
+}
+
+// lastValue returns the last element of a series (0 if empty)
+func lastValue(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
+
+// SizingLimits caps what any sizer may return
+type SizingLimits struct {
+	MaxNotionalPercent float64 // Max notional per position as % of equity (0 = off)
+	MaxLeverage        float64 // Max total open notional as a multiple of equity (0 = off)
+	MinNotional        float64 // Exchange minimum order value; smaller sizes are skipped
+}
+
+// DefaultSizingLimits returns the configured limits
+func DefaultSizingLimits() SizingLimits {
+	return SizingLimits{
+		MaxNotionalPercent: MAX_POSITION_NOTIONAL_PERCENT,
+		MaxLeverage:        MAX_LEVERAGE,
+		MinNotional:        DEFAULT_MIN_NOTIONAL,
+	}
+}
+
+// SizePosition runs the sizer, applies the score factor and enforces the
+// limits. Returns the notional (0 = don't trade) and a note on any cap applied.
+func SizePosition(sizer PositionSizer, in SizingInput, limits SizingLimits) (float64, string) {
+	if sizer == nil {
+		sizer = FixedNotionalSizer{}
+	}
+
+	size := sizer.Size(in)
+	if in.ScoreFactor > 0 {
+		size *= in.ScoreFactor
+	}
+	if math.IsNaN(size) || math.IsInf(size, 0) || size <= 0 {
+		return 0, fmt.Sprintf("%s sizer returned no size", sizer.Name())
+	}
+
+	size, note := limits.Cap(size, in.Equity, in.OpenNotional)
+	if note != "" {
+		note = sizer.Name() + " " + note
+	}
+	if size < limits.MinNotional {
+		return 0, fmt.Sprintf("size $%.2f below minimum notional $%.2f", size, limits.MinNotional)
+	}
+
+	return size, note
+}
+
+// Cap applies the per-position and leverage limits to a position's notional,
+// given the notional of the other open positions
+func (limits SizingLimits) Cap(size, equity, openNotional float64) (float64, string) {
+	note := ""
+	if limits.MaxNotionalPercent > 0 {
+		if maxSize := equity * limits.MaxNotionalPercent / 100; size > maxSize {
+			note = fmt.Sprintf("size $%.2f capped to $%.2f (%.0f%% of equity)",
+				size, maxSize, limits.MaxNotionalPercent)
+			size = maxSize
+		}
+	}
+	if limits.MaxLeverage > 0 {
+		available := equity*limits.MaxLeverage - openNotional
+		if size > available {
+			note = fmt.Sprintf("size $%.2f capped to $%.2f (%.1fx leverage)",
+				size, math.Max(0, available), limits.MaxLeverage)
+			size = math.Max(0, available)
+		}
+	}
+	return size, note
+}
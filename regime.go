@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ==================== MARKET REGIME DETECTION ====================
+
+// Market regimes
+const (
+	REGIME_RANGE           = "range"
+	REGIME_TRENDING_UP     = "trending_up"
+	REGIME_TRENDING_DOWN   = "trending_down"
+	REGIME_HIGH_VOLATILITY = "high_volatility"
+	REGIME_UNKNOWN         = "unknown"
+)
+
+// Strategies that can be switched on/off per regime
+const (
+	STRATEGY_DIVERGENCE_FADE = "divergence_fade" // Short bearish RSI divergences at resistance
+)
+
+// Skip strategies disabled for the current regime (set from flags; see STRATEGY_REGIMES)
+var REGIME_FILTER_ENABLED = false
+
+// STRATEGY_REGIMES lists the regimes each strategy is allowed to trade in.
+// The divergence fade works in ranges and with a downtrend, but gets run over
+// in uptrends and volatility spikes.
+var STRATEGY_REGIMES = map[string]map[string]bool{
+	STRATEGY_DIVERGENCE_FADE: {
+		REGIME_RANGE:           true,
+		REGIME_TRENDING_DOWN:   true,
+		REGIME_TRENDING_UP:     false,
+		REGIME_HIGH_VOLATILITY: false,
+		REGIME_UNKNOWN:         true,
+	},
+}
+
+// RegimeConfig holds the regime classifier parameters
+type RegimeConfig struct {
+	ADXPeriod          int     // ADX / DI period (default: 14)
+	ADXTrendThreshold  float64 // ADX above this = trending (default: 25)
+	BBPeriod           int     // Bollinger Band period (default: 20)
+	BBStdDev           float64 // Bollinger Band width in standard deviations (default: 2)
+	PercentileWindow   int     // Candles used to rank ATR and bandwidth (default: 200)
+	HighVolPercentile  float64 // ATR percentile at/above this = high volatility (default: 90)
+	HTFMultiplier      int     // Higher timeframe = base interval * multiplier (default: 4)
+	HTFMAPeriod        int     // Moving average period on the higher timeframe (default: 50)
+	HTFSlopeBars       int     // Slope measured over this many HTF bars (default: 5)
+	HTFSlopeThreshold  float64 // |slope| in % above this confirms a trend (default: 0.1)
+	ExpansionThreshold float64 // Bandwidth percentile above this supports high volatility (default: 80)
+}
+
+// DefaultRegimeConfig returns the default classifier parameters
+func DefaultRegimeConfig() RegimeConfig {
+	return RegimeConfig{
+		ADXPeriod:          14,
+		ADXTrendThreshold:  25,
+		BBPeriod:           20,
+		BBStdDev:           2,
+		PercentileWindow:   200,
+		HighVolPercentile:  90,
+		HTFMultiplier:      4,
+		HTFMAPeriod:        50,
+		HTFSlopeBars:       5,
+		HTFSlopeThreshold:  0.1,
+		ExpansionThreshold: 80,
+	}
+}
+
+// MarketRegime is the classifier output with the inputs that produced it
+type MarketRegime struct {
+	Regime            string
+	ADX               float64
+	PlusDI            float64
+	MinusDI           float64
+	BBWidth           float64 // (upper - lower) / middle * 100
+	BBWidthPercentile float64 // 0-100
+	ATRPercentile     float64 // 0-100
+	HTFInterval       string
+	HTFSlope          float64 // % change of the HTF MA over HTFSlopeBars (0 if unavailable)
+}
+
+// calcADX returns ADX, +DI and -DI using Wilder smoothing
+func calcADX(candles []Candle, period int) ([]float64, []float64, []float64) {
+	n := len(candles)
+	adx := make([]float64, n)
+	plusDI := make([]float64, n)
+	minusDI := make([]float64, n)
+	if n < 2*period+1 {
+		return adx, plusDI, minusDI
+	}
+
+	var trSum, plusDMSum, minusDMSum float64
+	dx := make([]float64, n)
+
+	for i := 1; i < n; i++ {
+		upMove := candles[i].High - candles[i-1].High
+		downMove := candles[i-1].Low - candles[i].Low
+		plusDM, minusDM := 0.0, 0.0
+		if upMove > downMove && upMove > 0 {
+			plusDM = upMove
+		}
+		if downMove > upMove && downMove > 0 {
+			minusDM = downMove
+		}
+		tr := math.Max(candles[i].High-candles[i].Low,
+			math.Max(math.Abs(candles[i].High-candles[i-1].Close), math.Abs(candles[i].Low-candles[i-1].Close)))
+
+		if i <= period {
+			trSum += tr
+			plusDMSum += plusDM
+			minusDMSum += minusDM
+			if i < period {
+				continue
+			}
+		} else {
+			trSum = trSum - trSum/float64(period) + tr
+			plusDMSum = plusDMSum - plusDMSum/float64(period) + plusDM
+			minusDMSum = minusDMSum - minusDMSum/float64(period) + minusDM
+		}
+
+		if trSum > 0 {
+			plusDI[i] = 100 * plusDMSum / trSum
+			minusDI[i] = 100 * minusDMSum / trSum
+		}
+		if sum := plusDI[i] + minusDI[i]; sum > 0 {
+			dx[i] = 100 * math.Abs(plusDI[i]-minusDI[i]) / sum
+		}
+	}
+
+	// First ADX is the average DX, then Wilder smoothing
+	start := 2 * period
+	sum := 0.0
+	for i := period; i < start; i++ {
+		sum += dx[i]
+	}
+	adx[start-1] = sum / float64(period)
+	for i := start; i < n; i++ {
+		adx[i] = (adx[i-1]*float64(period-1) + dx[i]) / float64(period)
+	}
+
+	return adx, plusDI, minusDI
+}
+
+// calcBollingerWidth returns (upper - lower) / middle * 100 for each candle
+func calcBollingerWidth(closes []float64, period int, stdDev float64) []float64 {
+	width := make([]float64, len(closes))
+	for i := period - 1; i < len(closes); i++ {
+		mean := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			mean += closes[j]
+		}
+		mean /= float64(period)
+
+		variance := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			variance += (closes[j] - mean) * (closes[j] - mean)
+		}
+		sd := math.Sqrt(variance / float64(period))
+
+		if mean > 0 {
+			width[i] = 2 * stdDev * sd / mean * 100
+		}
+	}
+	return width
+}
+
+// calcSMA returns the simple moving average (0 until enough values)
+func calcSMA(values []float64, period int) []float64 {
+	sma := make([]float64, len(values))
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			sma[i] = sum / float64(period)
+		}
+	}
+	return sma
+}
+
+// percentileRank returns where the last value sits among the trailing window
+// of non-zero values (0-100)
+func percentileRank(values []float64, window int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	current := values[len(values)-1]
+	start := len(values) - window
+	if start < 0 {
+		start = 0
+	}
+
+	below, total := 0, 0
+	for _, v := range values[start:] {
+		if v <= 0 {
+			continue
+		}
+		total++
+		if v <= current {
+			below++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(below) / float64(total) * 100
+}
+
+// htfSlope resamples the base candles to interval*multiplier and returns the
+// % slope of the HTF moving average, with the HTF interval used
+func htfSlope(candles []Candle, interval string, config RegimeConfig) (float64, string) {
+	base, err := parseIntervalDuration(interval)
+	if err != nil || config.HTFMultiplier <= 1 {
+		return 0, ""
+	}
+	htfInterval := formatIntervalDuration(base * time.Duration(config.HTFMultiplier))
+
+	htf, err := ResampleCandles(candles, htfInterval, false)
+	if err != nil || len(htf) < config.HTFMAPeriod+config.HTFSlopeBars {
+		return 0, htfInterval
+	}
+
+	closes := make([]float64, len(htf))
+	for i, c := range htf {
+		closes[i] = c.Close
+	}
+	ma := calcSMA(closes, config.HTFMAPeriod)
+	last := ma[len(ma)-1]
+	prev := ma[len(ma)-1-config.HTFSlopeBars]
+	if prev <= 0 {
+		return 0, htfInterval
+	}
+
+	return (last - prev) / prev * 100, htfInterval
+}
+
+// ClassifyRegime tags the market as trending, ranging or highly volatile
+func ClassifyRegime(candles []Candle, interval string, config RegimeConfig) *MarketRegime {
+	regime := &MarketRegime{Regime: REGIME_UNKNOWN}
+	if len(candles) < 2*config.ADXPeriod+1 || len(candles) < config.BBPeriod {
+		return regime
+	}
+
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+	}
+
+	adx, plusDI, minusDI := calcADX(candles, config.ADXPeriod)
+	last := len(candles) - 1
+	regime.ADX = adx[last]
+	regime.PlusDI = plusDI[last]
+	regime.MinusDI = minusDI[last]
+
+	bbWidth := calcBollingerWidth(closes, config.BBPeriod, config.BBStdDev)
+	regime.BBWidth = bbWidth[last]
+	regime.BBWidthPercentile = percentileRank(bbWidth, config.PercentileWindow)
+
+	atr := calcATR(candles, config.ADXPeriod)
+	regime.ATRPercentile = percentileRank(atr, config.PercentileWindow)
+
+	regime.HTFSlope, regime.HTFInterval = htfSlope(candles, interval, config)
+
+	// Volatility spike dominates: ATR extreme with expanding bands
+	if regime.ATRPercentile >= config.HighVolPercentile && regime.BBWidthPercentile >= config.ExpansionThreshold {
+		regime.Regime = REGIME_HIGH_VOLATILITY
+		return regime
+	}
+
+	// Trend: strong ADX, direction from DI, not contradicted by the HTF slope
+	if regime.ADX >= config.ADXTrendThreshold {
+		up := regime.PlusDI > regime.MinusDI
+		htfAgrees := math.Abs(regime.HTFSlope) < config.HTFSlopeThreshold ||
+			(up && regime.HTFSlope > 0) || (!up && regime.HTFSlope < 0)
+		if htfAgrees {
+			if up {
+				regime.Regime = REGIME_TRENDING_UP
+			} else {
+				regime.Regime = REGIME_TRENDING_DOWN
+			}
+			return regime
+		}
+	}
+
+	// Weak ADX but a steep HTF slope still counts as a trend
+	if regime.HTFSlope >= config.HTFSlopeThreshold*2 {
+		regime.Regime = REGIME_TRENDING_UP
+	} else if regime.HTFSlope <= -config.HTFSlopeThreshold*2 {
+		regime.Regime = REGIME_TRENDING_DOWN
+	} else {
+		regime.Regime = REGIME_RANGE
+	}
+	return regime
+}
+
+// DetectRegime classifies the current regime from the time-based klines
+func (e *TradingEngine) DetectRegime() {
+	candles := e.RawCandles
+	if len(candles) == 0 {
+		candles = e.Candles
+	}
+	e.Regime = ClassifyRegime(candles, e.Interval, DefaultRegimeConfig())
+}
+
+// CurrentRegime returns the detected regime name
+func (e *TradingEngine) CurrentRegime() string {
+	if e.Regime == nil {
+		return REGIME_UNKNOWN
+	}
+	return e.Regime.Regime
+}
+
+// strategyEnabled reports whether a strategy may trade in the given regime
+func strategyEnabled(strategy, regime string) bool {
+	if !REGIME_FILTER_ENABLED {
+		return true
+	}
+	allowed, exists := STRATEGY_REGIMES[strategy]
+	if !exists {
+		return true
+	}
+	return allowed[regime]
+}
+
+// regimeAllows checks the strategy against the engine's regime and logs skips
+func (e *TradingEngine) regimeAllows(strategy string) bool {
+	if strategyEnabled(strategy, e.CurrentRegime()) {
+		return true
+	}
+	if VERBOSE_MODE {
+		fmt.Printf("   ⏸️  [%s] %s disabled in %s regime\n", e.Symbol, strategy, e.CurrentRegime())
+	}
+	return false
+}
+
+// printRegime displays the regime and its inputs
+func (e *TradingEngine) printRegime() {
+	if e.Regime == nil {
+		return
+	}
+	r := e.Regime
+	fmt.Printf("✅ Regime: %s (ADX: %.1f, +DI: %.1f, -DI: %.1f, ATR pct: %.0f, BB width pct: %.0f",
+		r.Regime, r.ADX, r.PlusDI, r.MinusDI, r.ATRPercentile, r.BBWidthPercentile)
+	if r.HTFInterval != "" {
+		fmt.Printf(", %s MA slope: %+.2f%%", r.HTFInterval, r.HTFSlope)
+	}
+	fmt.Println(")")
+}
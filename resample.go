@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==================== CANDLE RESAMPLING ====================
+
+// binanceIntervals lists the kline intervals the Binance API serves natively
+var binanceIntervals = map[string]bool{
+	"1m": true, "3m": true, "5m": true, "15m": true, "30m": true,
+	"1h": true, "2h": true, "4h": true, "6h": true, "8h": true, "12h": true,
+	"1d": true, "3d": true, "1w": true, "1M": true,
+}
+
+// isBinanceInterval reports whether the interval can be fetched directly
+func isBinanceInterval(interval string) bool {
+	return binanceIntervals[interval]
+}
+
+// parseIntervalDuration converts any "<N><unit>" interval (7m, 90m, 2h, 3d, 1w)
+// to a duration. Months ("1M") have no fixed length and are rejected.
+func parseIntervalDuration(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+
+	unit := interval[len(interval)-1:]
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+
+	switch unit {
+	case "m":
+		return time.Duration(n) * time.Minute, nil
+	case "h":
+		return time.Duration(n) * time.Hour, nil
+	case "d":
+		return time.Duration(n) * 24 * time.Hour, nil
+	case "w":
+		return time.Duration(n) * 7 * 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unsupported interval unit in %q", interval)
+	}
+}
+
+// formatIntervalDuration is the inverse of parseIntervalDuration, using the
+// largest unit that divides the duration evenly (e.g. 16h, 2d, 90m)
+func formatIntervalDuration(d time.Duration) string {
+	switch {
+	case d%(7*24*time.Hour) == 0:
+		return fmt.Sprintf("%dw", d/(7*24*time.Hour))
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// ResampleCandles aggregates candles into a larger interval.
+//
+// Buckets are aligned with time.Truncate on UTC open times, the same alignment
+// parseCandleDuration/WaitForCandleClose use (e.g. 15m buckets start at :00,
+// :15, ...; 1d at 00:00 UTC; 1w on Monday). The trailing bucket is kept only if
+// includePartial is true or the source candles cover it completely.
+func ResampleCandles(candles []Candle, interval string, includePartial bool) ([]Candle, error) {
+	target, err := parseIntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) == 0 {
+		return nil, nil
+	}
+
+	var resampled []Candle
+	var current Candle
+	var bucketStart time.Time
+	var lastCloseTime time.Time
+	inBucket := false
+
+	flush := func() {
+		current.CloseTime = bucketStart.Add(target - time.Millisecond)
+		resampled = append(resampled, current)
+	}
+
+	for _, c := range candles {
+		start := c.OpenTime.UTC().Truncate(target)
+
+		if inBucket && start.Before(bucketStart) {
+			return nil, fmt.Errorf("candles out of order at %s", c.OpenTime.Format("2006-01-02 15:04"))
+		}
+
+		if !inBucket || !start.Equal(bucketStart) {
+			if inBucket {
+				flush()
+			}
+			bucketStart = start
+			inBucket = true
+			current = Candle{
+				OpenTime: start,
+				Open:     c.Open,
+				High:     c.High,
+				Low:      c.Low,
+			}
+		}
+
+		if c.High > current.High {
+			current.High = c.High
+		}
+		if c.Low < current.Low {
+			current.Low = c.Low
+		}
+		current.Close = c.Close
+		current.Volume += c.Volume
+		current.QuoteAssetVolume += c.QuoteAssetVolume
+		current.NumberOfTrades += c.NumberOfTrades
+		current.TakerBuyBaseAssetVolume += c.TakerBuyBaseAssetVolume
+		current.TakerBuyQuoteAssetVolume += c.TakerBuyQuoteAssetVolume
+		lastCloseTime = c.CloseTime
+	}
+
+	// Trailing bucket: complete only if source data reaches the bucket's close
+	bucketClose := bucketStart.Add(target - time.Millisecond)
+	if includePartial || !lastCloseTime.Before(bucketClose) {
+		flush()
+	}
+
+	return resampled, nil
+}
+
+// sourceCandlesNeeded returns how many sourceInterval candles are needed to
+// build `limit` candles of the target interval
+func sourceCandlesNeeded(interval, sourceInterval string, limit int) (int, error) {
+	target, err := parseIntervalDuration(interval)
+	if err != nil {
+		return 0, err
+	}
+	source, err := parseIntervalDuration(sourceInterval)
+	if err != nil {
+		return 0, err
+	}
+	if target < source || target%source != 0 {
+		return 0, fmt.Errorf("cannot build %s candles from %s candles", interval, sourceInterval)
+	}
+
+	// One extra target candle so the oldest bucket is not cut in half
+	return (limit + 1) * int(target/source), nil
+}
+
+// resampleToLimit resamples source candles and trims the result to `limit`
+// candles, dropping the oldest bucket since it is usually incomplete
+func resampleToLimit(sourceCandles []Candle, interval string, limit int) ([]Candle, error) {
+	candles, err := ResampleCandles(sourceCandles, interval, RESAMPLE_INCLUDE_PARTIAL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candles) > 1 {
+		candles = candles[1:]
+	}
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+
+	return candles, nil
+}
+
+// resolveSourceInterval picks the interval to fetch from: the configured source
+// interval, or 1m when the target is a custom interval Binance cannot serve
+func resolveSourceInterval(interval, sourceInterval string) string {
+	sourceInterval = strings.TrimSpace(sourceInterval)
+	if sourceInterval == interval {
+		return ""
+	}
+	if sourceInterval == "" && !isBinanceInterval(interval) {
+		return "1m"
+	}
+	return sourceInterval
+}
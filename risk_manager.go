@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ==================== PORTFOLIO RISK MANAGER ====================
+
+// Actions taken when a limit is breached
+const (
+	RISK_ACTION_HALT    = "halt"    // Stop new entries, keep managing open positions
+	RISK_ACTION_FLATTEN = "flatten" // Stop new entries and close every open position
+)
+
+// Rules for lifting a halt
+const (
+	RISK_RESUME_NEXT_DAY = "next_day" // At the next UTC midnight
+	RISK_RESUME_AFTER    = "after"    // After RiskConfig.ResumeAfter
+	RISK_RESUME_MANUAL   = "manual"   // Only on restart
+)
+
+// Limit names
+const (
+	RISK_LIMIT_DAILY_LOSS  = "daily_loss"
+	RISK_LIMIT_DRAWDOWN    = "drawdown"
+	RISK_LIMIT_CONSECUTIVE = "consecutive_losses"
+)
+
+// Trading states
+const (
+	RISK_TRADING_ACTIVE = "ACTIVE"
+	RISK_TRADING_HALTED = "HALTED"
+)
+
+// Transitions kept for the status display
+const RISK_EVENT_HISTORY_SIZE = 20
+
+// Breach action (set from flags)
+var RISK_BREACH_ACTION = RISK_ACTION_HALT
+
+// RiskConfig holds the portfolio limits and how each one resumes
+type RiskConfig struct {
+	MaxDailyLossPercent  float64           // Loss from the UTC day's starting equity (0 = off)
+	MaxDrawdownPercent   float64           // Peak-to-trough equity drop (0 = off)
+	MaxConsecutiveLosses int               // Losing closes in a row (0 = off)
+	BreachAction         string            // RISK_ACTION_HALT or RISK_ACTION_FLATTEN
+	ResumeRules          map[string]string // Limit -> RISK_RESUME_* rule
+	ResumeAfter          time.Duration     // Wait for RISK_RESUME_AFTER
+}
+
+// DefaultRiskConfig returns the default limits
+func DefaultRiskConfig() RiskConfig {
+	return RiskConfig{
+		MaxDailyLossPercent:  MAX_DAILY_LOSS_PERCENT,
+		MaxDrawdownPercent:   MAX_DRAWDOWN_PERCENT,
+		MaxConsecutiveLosses: MAX_CONSECUTIVE_LOSSES,
+		BreachAction:         RISK_BREACH_ACTION,
+		ResumeRules: map[string]string{
+			RISK_LIMIT_DAILY_LOSS:  RISK_RESUME_NEXT_DAY,
+			RISK_LIMIT_DRAWDOWN:    RISK_RESUME_NEXT_DAY,
+			RISK_LIMIT_CONSECUTIVE: RISK_RESUME_AFTER,
+		},
+		ResumeAfter: RISK_RESUME_AFTER_MINUTES * time.Minute,
+	}
+}
+
+// RiskEvent is a logged halt/resume transition
+type RiskEvent struct {
+	Time    time.Time
+	State   string // RISK_TRADING_ACTIVE or RISK_TRADING_HALTED
+	Limit   string // Limit that triggered the transition (empty on resume)
+	Message string
+	Equity  float64
+}
+
+// RiskManager tracks realized + unrealized equity against the portfolio limits
+type RiskManager struct {
+	Config RiskConfig
+
+	Equity            float64
+	PeakEquity        float64
+	DayStartEquity    float64
+	Day               string // UTC date DayStartEquity belongs to
+	ConsecutiveLosses int
+
+	Halted     bool
+	HaltLimit  string
+	HaltReason string
+	HaltedAt   time.Time
+	ResumeAt   time.Time // Zero = manual resume
+
+	Events []RiskEvent
+	mutex  sync.Mutex
+}
+
+// NewRiskManager creates a manager starting from the given equity
+func NewRiskManager(config RiskConfig, startingEquity float64) *RiskManager {
+	return &RiskManager{
+		Config:         config,
+		Equity:         startingEquity,
+		PeakEquity:     startingEquity,
+		DayStartEquity: startingEquity,
+		Day:            time.Now().UTC().Format("2006-01-02"),
+	}
+}
+
+// DailyLossPercent returns the loss from the day's starting equity (positive = loss)
+func (r *RiskManager) DailyLossPercent() float64 {
+	if r.DayStartEquity <= 0 {
+		return 0
+	}
+	return (r.DayStartEquity - r.Equity) / r.DayStartEquity * 100
+}
+
+// DrawdownPercent returns the drop from peak equity
+func (r *RiskManager) DrawdownPercent() float64 {
+	if r.PeakEquity <= 0 {
+		return 0
+	}
+	return (r.PeakEquity - r.Equity) / r.PeakEquity * 100
+}
+
+// logEvent records and prints a transition (caller holds the lock)
+func (r *RiskManager) logEvent(now time.Time, state, limit, message string) {
+	r.Events = append(r.Events, RiskEvent{Time: now, State: state, Limit: limit, Message: message, Equity: r.Equity})
+	if len(r.Events) > RISK_EVENT_HISTORY_SIZE {
+		r.Events = r.Events[len(r.Events)-RISK_EVENT_HISTORY_SIZE:]
+	}
+
+	icon := "🟢"
+	if state == RISK_TRADING_HALTED {
+		icon = "🛑"
+	}
+	fmt.Printf("\n%s RISK %s [%s] %s (equity $%.2f)\n", icon, state, now.UTC().Format("2006-01-02 15:04:05 UTC"), message, r.Equity)
+}
+
+// resumeTime returns when a halt on limit lifts (zero = manual)
+func (r *RiskManager) resumeTime(limit string, now time.Time) time.Time {
+	switch r.Config.ResumeRules[limit] {
+	case RISK_RESUME_NEXT_DAY:
+		day := now.UTC()
+		return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, time.UTC)
+	case RISK_RESUME_AFTER:
+		return now.Add(r.Config.ResumeAfter)
+	default:
+		return time.Time{}
+	}
+}
+
+// halt stops new entries (caller holds the lock)
+func (r *RiskManager) halt(now time.Time, limit, reason string) {
+	r.Halted = true
+	r.HaltLimit = limit
+	r.HaltReason = reason
+	r.HaltedAt = now
+	r.ResumeAt = r.resumeTime(limit, now)
+
+	resume := "manual restart"
+	if !r.ResumeAt.IsZero() {
+		resume = r.ResumeAt.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	r.logEvent(now, RISK_TRADING_HALTED, limit,
+		fmt.Sprintf("%s - action: %s, resumes: %s", reason, r.Config.BreachAction, resume))
+}
+
+// resume lifts the halt and resets the state that caused it (caller holds the lock)
+func (r *RiskManager) resume(now time.Time) {
+	switch r.HaltLimit {
+	case RISK_LIMIT_DRAWDOWN:
+		r.PeakEquity = r.Equity // Measure the next drawdown from here
+	case RISK_LIMIT_CONSECUTIVE:
+		r.ConsecutiveLosses = 0
+	}
+
+	limit := r.HaltLimit
+	r.Halted = false
+	r.HaltLimit = ""
+	r.HaltReason = ""
+	r.ResumeAt = time.Time{}
+	r.logEvent(now, RISK_TRADING_ACTIVE, "", fmt.Sprintf("resumed after %s halt", limit))
+}
+
+// RecordTradeResult counts consecutive losing closes
+func (r *RiskManager) RecordTradeResult(profitLoss float64) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if profitLoss > 0 {
+		r.ConsecutiveLosses = 0
+	} else {
+		r.ConsecutiveLosses++
+	}
+}
+
+// Update feeds the current equity, rolls the UTC day, applies resume rules and
+// checks the limits. Returns RISK_ACTION_FLATTEN when a new breach requires
+// closing all positions, otherwise "".
+func (r *RiskManager) Update(equity float64, now time.Time) string {
+	if r == nil {
+		return ""
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.Equity = equity
+	if day := now.UTC().Format("2006-01-02"); day != r.Day {
+		r.Day = day
+		r.DayStartEquity = equity
+	}
+
+	if r.Halted {
+		if !r.ResumeAt.IsZero() && !now.Before(r.ResumeAt) {
+			r.resume(now)
+		} else {
+			return ""
+		}
+	}
+
+	if equity > r.PeakEquity {
+		r.PeakEquity = equity
+	}
+
+	limit, reason := "", ""
+	if r.Config.MaxDailyLossPercent > 0 && r.DailyLossPercent() >= r.Config.MaxDailyLossPercent {
+		limit = RISK_LIMIT_DAILY_LOSS
+		reason = fmt.Sprintf("daily loss %.2f%% >= %.2f%%", r.DailyLossPercent(), r.Config.MaxDailyLossPercent)
+	} else if r.Config.MaxDrawdownPercent > 0 && r.DrawdownPercent() >= r.Config.MaxDrawdownPercent {
+		limit = RISK_LIMIT_DRAWDOWN
+		reason = fmt.Sprintf("drawdown %.2f%% >= %.2f%%", r.DrawdownPercent(), r.Config.MaxDrawdownPercent)
+	} else if r.Config.MaxConsecutiveLosses > 0 && r.ConsecutiveLosses >= r.Config.MaxConsecutiveLosses {
+		limit = RISK_LIMIT_CONSECUTIVE
+		reason = fmt.Sprintf("%d consecutive losses", r.ConsecutiveLosses)
+	}
+	if limit == "" {
+		return ""
+	}
+
+	r.halt(now, limit, reason)
+	if r.Config.BreachAction == RISK_ACTION_FLATTEN {
+		return RISK_ACTION_FLATTEN
+	}
+	return ""
+}
+
+// EntriesAllowed reports whether new entries may open, with the halt reason if not
+func (r *RiskManager) EntriesAllowed() (bool, string) {
+	if r == nil {
+		return true, ""
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.Halted {
+		return false, r.HaltReason
+	}
+	return true, ""
+}
+
+// riskAllowsEntries checks the risk manager and prints why entries are paused
+func riskAllowsEntries(r *RiskManager) bool {
+	allowed, reason := r.EntriesAllowed()
+	if !allowed {
+		fmt.Printf("\n🛑 New entries halted by risk manager: %s\n", reason)
+	}
+	return allowed
+}
+
+// Print shows the current risk state and recent transitions
+func (r *RiskManager) Print() {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	state := RISK_TRADING_ACTIVE + " ✅"
+	if r.Halted {
+		state = RISK_TRADING_HALTED + " 🛑"
+	}
+
+	fmt.Printf("\n🛡️  Risk Manager: %s\n", state)
+	fmt.Printf("   Equity:        $%.2f (peak $%.2f)\n", r.Equity, r.PeakEquity)
+	fmt.Printf("   Daily Loss:    %.2f%% / %.2f%%\n", r.DailyLossPercent(), r.Config.MaxDailyLossPercent)
+	fmt.Printf("   Drawdown:      %.2f%% / %.2f%%\n", r.DrawdownPercent(), r.Config.MaxDrawdownPercent)
+	fmt.Printf("   Loss Streak:   %d / %d\n", r.ConsecutiveLosses, r.Config.MaxConsecutiveLosses)
+	if r.Halted {
+		resume := "manual restart"
+		if !r.ResumeAt.IsZero() {
+			resume = r.ResumeAt.UTC().Format("2006-01-02 15:04 UTC")
+		}
+		fmt.Printf("   Halted:        %s since %s, resumes %s\n",
+			r.HaltReason, r.HaltedAt.UTC().Format("15:04 UTC"), resume)
+	}
+
+	if len(r.Events) > 0 {
+		fmt.Println("   Recent transitions:")
+		start := len(r.Events) - 5
+		if start < 0 {
+			start = 0
+		}
+		for _, e := range r.Events[start:] {
+			fmt.Printf("     %s %s %s\n", e.Time.UTC().Format("01-02 15:04"), e.State, e.Message)
+		}
+	}
+}
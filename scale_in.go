@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ==================== SCALE-IN ====================
+
+// Active scale-in mode (set from flags)
+var SCALE_IN_ENABLED = false
+
+// TradeFill is one entry leg of a position
+type TradeFill struct {
+	Time     time.Time
+	Price    float64
+	Quantity float64
+}
+
+// formatFillLegs renders entry legs for the CSV as "qty@price" joined by ";"
+func formatFillLegs(fills []TradeFill) string {
+	legs := make([]string, len(fills))
+	for i, fill := range fills {
+		legs[i] = fmt.Sprintf("%.8g@%.4f", fill.Quantity, fill.Price)
+	}
+	return strings.Join(legs, ";")
+}
+
+// ScaleInRule adds to a position when price retests the zone it was taken
+// from without breaking through it. Each trade carries its own rule.
+type ScaleInRule struct {
+	ZoneBot          float64 // Zone the entry traded off (resistance for shorts)
+	ZoneTop          float64
+	AddPercent       float64 // Each add as % of the first leg's quantity
+	MaxAdds          int
+	TolerancePercent float64 // How near the zone edge counts as a retest
+}
+
+// NewScaleInRule returns the configured rule for a zone, or nil when
+// scale-ins are off or there's no zone to retest
+func NewScaleInRule(zoneBot, zoneTop float64) *ScaleInRule {
+	if !SCALE_IN_ENABLED || zoneBot <= 0 || zoneTop < zoneBot {
+		return nil
+	}
+	return &ScaleInRule{
+		ZoneBot:          zoneBot,
+		ZoneTop:          zoneTop,
+		AddPercent:       SCALE_IN_ADD_PERCENT,
+		MaxAdds:          SCALE_IN_MAX_ADDS,
+		TolerancePercent: SCALE_IN_RETEST_TOLERANCE_PERCENT,
+	}
+}
+
+// Retested reports whether the candle retested the zone and held: a short's
+// high reaches the zone bottom but closes under the top and stays below the
+// stop (mirrored for longs at support).
+func (r *ScaleInRule) Retested(side string, candle Candle, stopLoss float64) bool {
+	if side == "SHORT" {
+		return candle.High >= r.ZoneBot*(1-r.TolerancePercent/100) &&
+			candle.Close < r.ZoneTop && candle.High < stopLoss
+	}
+	return candle.Low <= r.ZoneTop*(1+r.TolerancePercent/100) &&
+		candle.Close > r.ZoneBot && candle.Low > stopLoss
+}
+
+// checkScaleIn adds to a trade when its rule fires on a candle that opened
+// after the last leg. Adds pass the same gates as new entries (caller holds mp.mutex).
+func (mp *MultiPaperTradingEngine) checkScaleIn(trade *PaperTrade, candle Candle, currentPrices map[string]float64) {
+	price := currentPrices[trade.Symbol]
+	rule := trade.ScaleIn
+	if rule == nil || len(trade.Fills) == 0 || len(trade.Fills)-1 >= rule.MaxAdds {
+		return
+	}
+
+	// No adds after a partial exit, and one per candle
+	filled := 0.0
+	for _, fill := range trade.Fills {
+		filled += fill.Quantity
+	}
+	if trade.Quantity < filled*(1-1e-9) || !candle.OpenTime.After(trade.Fills[len(trade.Fills)-1].Time) {
+		return
+	}
+	if !rule.Retested(trade.Side, candle, trade.StopLoss) {
+		return
+	}
+
+	quantity, reason := mp.allowedScaleIn(trade, trade.Fills[0].Quantity*rule.AddPercent/100, price, currentPrices)
+	if quantity <= 0 {
+		if VERBOSE_MODE {
+			fmt.Printf("   ⏸️  [%s] Scale-in skipped: %s\n", trade.Symbol, reason)
+		}
+		return
+	}
+	if reason != "" {
+		fmt.Printf("   ⚠️  [%s] Scale-in %s\n", trade.Symbol, reason)
+	}
+	order, err := mp.Broker.PlaceOrder(OrderRequest{
+		Symbol:   trade.Symbol,
+		Side:     entryOrderSide(trade.Side),
+		Type:     ORDER_TYPE_MARKET,
+		Quantity: quantity,
+		Price:    price,
+	})
+	if err != nil {
+		fmt.Printf("   ⛔ [%s] Scale-in order failed: %v\n", trade.Symbol, err)
+		return
+	}
+	if order.ExecutedQty > 0 && order.AvgPrice > 0 {
+		price, quantity = order.AvgPrice, order.ExecutedQty
+	}
+
+	// Weighted average entry over all legs
+	oldEntry := trade.EntryPrice
+	trade.Fills = append(trade.Fills, TradeFill{Time: time.Now(), Price: price, Quantity: quantity})
+	trade.EntryPrice = (trade.EntryPrice*trade.Quantity + price*quantity) / (trade.Quantity + quantity)
+	trade.Quantity += quantity
+	trade.Size = trade.Quantity * trade.EntryPrice
+	if risk := signedNotional(trade.Side, trade.EntryPrice-trade.StopLoss); risk > 0 {
+		trade.RiskReward = signedNotional(trade.Side, trade.TakeProfit-trade.EntryPrice) / risk
+	}
+
+	// Stop and target cover the new quantity
+	cancelExitOrders(mp.Broker, trade)
+	placeExitOrders(mp.Broker, trade)
+
+	if mp.TradeManager != nil && mp.TradeManager.IsEnabled() {
+		if err := mp.TradeManager.ScaleIn(trade.Symbol, price, quantity*price); err != nil && VERBOSE_MODE {
+			fmt.Printf("⚠️  Trade manager scale-in for %s: %v\n", trade.Symbol, err)
+		}
+	}
+
+	fmt.Printf("\n➕ [%s] SCALED IN %.8g @ $%.4f (zone retest, add %d/%d) | Avg entry: $%.4f → $%.4f | Size: $%.2f\n",
+		trade.Symbol, quantity, price, len(trade.Fills)-1, rule.MaxAdds, oldEntry, trade.EntryPrice, trade.Size)
+}
+
+// allowedScaleIn runs an add through the entry gates: session filter, risk
+// manager, sizing limits, correlation exposure caps and book depth. Returns the
+// quantity allowed (0 = skip) and the reason for any change. (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) allowedScaleIn(trade *PaperTrade, quantity, price float64,
+	currentPrices map[string]float64) (float64, string) {
+	if allowed, reason := SESSION_FILTER.EntryAllowed(time.Now()); !allowed {
+		return 0, reason
+	}
+	if allowed, reason := mp.Risk.EntriesAllowed(); !allowed {
+		return 0, "risk manager: " + reason
+	}
+
+	// The caps apply to the whole position after the add
+	otherNotional, others := mp.openExposure(trade.Symbol)
+	equity := mp.equity(currentPrices)
+	limits := sizingLimitsFor(trade.Symbol)
+	total, note := limits.Cap(trade.Size+quantity*price, equity, otherNotional)
+
+	exposureLimits := DefaultExposureLimits()
+	exposureLimits.MinNotional = limits.MinNotional
+	total, exposureNote := CheckExposure(trade.Symbol, trade.Side, total, equity, others,
+		mp.Correlations, exposureLimits)
+	if exposureNote != "" {
+		note = exposureNote
+	}
+
+	add := total - trade.Size
+	if add < limits.MinNotional || add <= 0 {
+		if note == "" {
+			note = fmt.Sprintf("add $%.2f below minimum notional $%.2f", add, limits.MinNotional)
+		}
+		return 0, note
+	}
+
+	if DEPTH_SOURCE != nil {
+		if _, err := DEPTH_SOURCE.CheckDepth(trade.Symbol, entryOrderSide(trade.Side), price, add); err != nil {
+			return 0, fmt.Sprintf("rejected by depth: %v", err)
+		}
+	}
+
+	return EXCHANGE_FILTERS.Get(trade.Symbol).RoundQuantity(add / price), note
+}
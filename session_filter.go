@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==================== SESSION & BLACKOUT FILTERS ====================
+
+// Binance perpetual funding settles at these UTC hours
+var FUNDING_HOURS_UTC = []int{0, 8, 16}
+
+// SessionWindow is a recurring daily window in a time zone. Block windows stop
+// new entries inside them; allow windows (if any are set) only permit entries inside them.
+type SessionWindow struct {
+	Name     string
+	Start    int            // Minutes after midnight
+	End      int            // Minutes after midnight (End < Start wraps past midnight)
+	Days     []time.Weekday // Days the window applies to (empty = every day)
+	Location *time.Location
+	Block    bool
+}
+
+// Contains reports whether t falls inside the window
+func (w SessionWindow) Contains(t time.Time) bool {
+	local := t.In(w.Location)
+	minute := local.Hour()*60 + local.Minute()
+
+	day := local.Weekday()
+	if w.Start > w.End && minute < w.End {
+		day = local.AddDate(0, 0, -1).Weekday() // After midnight: belongs to the previous day's window
+	}
+	if len(w.Days) > 0 {
+		matched := false
+		for _, d := range w.Days {
+			if d == day {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if w.Start <= w.End {
+		return minute >= w.Start && minute < w.End
+	}
+	return minute >= w.Start || minute < w.End
+}
+
+func (w SessionWindow) String() string { return w.Name }
+
+// CalendarEvent is a one-off blackout around a scheduled event (CPI, FOMC, ...)
+type CalendarEvent struct {
+	Name   string
+	Time   time.Time
+	Before time.Duration // Block entries this long before the event
+	After  time.Duration // ...and this long after it
+}
+
+// Contains reports whether t falls inside the event's blackout
+func (e CalendarEvent) Contains(t time.Time) bool {
+	return !t.Before(e.Time.Add(-e.Before)) && t.Before(e.Time.Add(e.After))
+}
+
+// SessionFilter decides whether new entries are allowed at a given time.
+// It only gates entries - open positions keep being managed.
+type SessionFilter struct {
+	Location *time.Location
+	Windows  []SessionWindow
+	Events   []CalendarEvent
+}
+
+// SESSION_FILTER is the active filter (set from flags; nil = always allowed)
+var SESSION_FILTER *SessionFilter
+
+// EntryAllowed reports whether a new entry may open at t, with the reason if not.
+// Takes the time explicitly so historical candles can be checked too.
+func (f *SessionFilter) EntryAllowed(t time.Time) (bool, string) {
+	if f == nil {
+		return true, ""
+	}
+
+	for _, e := range f.Events {
+		if e.Contains(t) {
+			return false, fmt.Sprintf("%s blackout (%s)", e.Name, e.Time.In(f.Location).Format("2006-01-02 15:04 MST"))
+		}
+	}
+
+	hasAllow, inAllow := false, false
+	for _, w := range f.Windows {
+		if w.Block {
+			if w.Contains(t) {
+				return false, w.Name
+			}
+			continue
+		}
+		hasAllow = true
+		if w.Contains(t) {
+			inAllow = true
+		}
+	}
+	if hasAllow && !inAllow {
+		return false, "outside trading sessions"
+	}
+
+	return true, ""
+}
+
+// sessionAllowsEntries checks SESSION_FILTER and prints why entries are paused
+func sessionAllowsEntries(now time.Time) bool {
+	allowed, reason := SESSION_FILTER.EntryAllowed(now)
+	if !allowed {
+		fmt.Printf("\n⏸️  New entries paused: %s (open positions still managed)\n", reason)
+	}
+	return allowed
+}
+
+// parseClock parses "HH:MM" into minutes after midnight
+func parseClock(s string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	h, err1 := strconv.Atoi(parts[0])
+	m, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || h < 0 || h > 24 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return h*60 + m, nil
+}
+
+// ParseSessionWindows parses comma-separated "HH:MM-HH:MM" windows in loc
+func ParseSessionWindows(spec string, loc *time.Location, block bool) ([]SessionWindow, error) {
+	var windows []SessionWindow
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		bounds := strings.Split(part, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid session %q (want HH:MM-HH:MM)", part)
+		}
+		start, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, SessionWindow{
+			Name:     "session " + part,
+			Start:    start,
+			End:      end,
+			Location: loc,
+			Block:    block,
+		})
+	}
+	return windows, nil
+}
+
+// ParseFlatWindows parses the --flat-window windows in the named time zone
+func ParseFlatWindows(spec, timezone string) ([]SessionWindow, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+	}
+	windows, err := ParseSessionWindows(spec, loc, true)
+	if err != nil {
+		return nil, err
+	}
+	for i := range windows {
+		windows[i].Name = strings.Replace(windows[i].Name, "session", "flat window", 1)
+	}
+	return windows, nil
+}
+
+// activeFlatWindow returns the flat window that contains t or starts within
+// FLAT_WINDOW_LEAD_MINUTES of it (nil = none)
+func activeFlatWindow(t time.Time) *SessionWindow {
+	for i, window := range FLAT_WINDOWS {
+		if window.Contains(t) || window.Contains(t.Add(FLAT_WINDOW_LEAD_MINUTES*time.Minute)) {
+			return &FLAT_WINDOWS[i]
+		}
+	}
+	return nil
+}
+
+// weekendWindow blocks Saturday and Sunday in loc
+func weekendWindow(loc *time.Location) SessionWindow {
+	return SessionWindow{
+		Name:     "weekend",
+		Start:    0,
+		End:      24 * 60,
+		Days:     []time.Weekday{time.Saturday, time.Sunday},
+		Location: loc,
+		Block:    true,
+	}
+}
+
+// fundingWindows block entries within `minutes` either side of each funding settlement
+func fundingWindows(minutes int) []SessionWindow {
+	var windows []SessionWindow
+	for _, hour := range FUNDING_HOURS_UTC {
+		settle := hour * 60
+		windows = append(windows, SessionWindow{
+			Name:     fmt.Sprintf("funding settlement %02d:00 UTC", hour),
+			Start:    (settle - minutes + 24*60) % (24 * 60),
+			End:      (settle + minutes) % (24 * 60),
+			Location: time.UTC,
+			Block:    true,
+		})
+	}
+	return windows
+}
+
+// LoadCalendarEvents reads blackout events from a CSV file:
+//
+//	# time (YYYY-MM-DD HH:MM in loc), name, minutes before, minutes after
+//	2025-01-15 13:30,CPI,30,60
+//
+// Minutes default to CALENDAR_BLACKOUT_MINUTES when omitted.
+func LoadCalendarEvents(filename string, loc *time.Location) ([]CalendarEvent, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calendar file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var events []CalendarEvent
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("calendar line %d: %w", line, err)
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("calendar line %d: want time,name[,before,after]", line)
+		}
+
+		eventTime, err := time.ParseInLocation("2006-01-02 15:04", strings.TrimSpace(record[0]), loc)
+		if err != nil {
+			return nil, fmt.Errorf("calendar line %d: %w", line, err)
+		}
+
+		event := CalendarEvent{
+			Name:   strings.TrimSpace(record[1]),
+			Time:   eventTime,
+			Before: CALENDAR_BLACKOUT_MINUTES * time.Minute,
+			After:  CALENDAR_BLACKOUT_MINUTES * time.Minute,
+		}
+		for i, target := range []*time.Duration{&event.Before, &event.After} {
+			if len(record) <= i+2 || strings.TrimSpace(record[i+2]) == "" {
+				continue
+			}
+			minutes, err := strconv.Atoi(strings.TrimSpace(record[i+2]))
+			if err != nil {
+				return nil, fmt.Errorf("calendar line %d: invalid minutes %q", line, record[i+2])
+			}
+			*target = time.Duration(minutes) * time.Minute
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// NewSessionFilter builds a filter from the session flags. Returns nil when
+// no window is configured.
+func NewSessionFilter(timezone, sessions, blackouts string, blockWeekend, blockFunding bool, calendarFile string) (*SessionFilter, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session time zone %q: %w", timezone, err)
+	}
+
+	filter := &SessionFilter{Location: loc}
+
+	allow, err := ParseSessionWindows(sessions, loc, false)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ParseSessionWindows(blackouts, loc, true)
+	if err != nil {
+		return nil, err
+	}
+	for i := range block {
+		block[i].Name = "blackout " + strings.TrimPrefix(block[i].Name, "session ")
+	}
+	filter.Windows = append(allow, block...)
+
+	if blockWeekend {
+		filter.Windows = append(filter.Windows, weekendWindow(loc))
+	}
+	if blockFunding {
+		filter.Windows = append(filter.Windows, fundingWindows(FUNDING_BLACKOUT_MINUTES)...)
+	}
+	if calendarFile != "" {
+		filter.Events, err = LoadCalendarEvents(calendarFile, loc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.Windows) == 0 && len(filter.Events) == 0 {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+// Print lists the configured windows and upcoming events
+func (f *SessionFilter) Print() {
+	if f == nil {
+		return
+	}
+	fmt.Printf("🕒 Session Filter (%s):\n", f.Location)
+	for _, w := range f.Windows {
+		kind := "allow"
+		if w.Block {
+			kind = "block"
+		}
+		fmt.Printf("   %s: %s\n", kind, w.Name)
+	}
+
+	upcoming := 0
+	for _, e := range f.Events {
+		if e.Time.Add(e.After).After(time.Now()) {
+			upcoming++
+		}
+	}
+	if len(f.Events) > 0 {
+		fmt.Printf("   calendar: %d event(s), %d upcoming\n", len(f.Events), upcoming)
+	}
+}
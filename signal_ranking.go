@@ -0,0 +1,391 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// ==================== SIGNAL RANKING & SLOT ALLOCATION ====================
+
+// Ranking criteria for multi-symbol candidates
+const (
+	RANK_BY_SCORE       = "score"  // Signal confidence score
+	RANK_BY_RISK_REWARD = "rr"     // Planned risk/reward
+	RANK_BY_VOLUME      = "volume" // 24h quote volume
+)
+
+// Ranking and replacement settings (set from flags)
+var (
+	RANK_SIGNALS_BY        = RANK_BY_SCORE
+	REPLACE_WEAK_POSITIONS = false // Close the weakest open position for a much stronger signal
+)
+
+// SignalCandidate is a fully evaluated short setup waiting for a slot
+type SignalCandidate struct {
+	Symbol       string
+	Entry        float64
+	StopLoss     float64
+	TakeProfit   float64
+	TPMethod     string
+	RiskReward   float64
+	RSI          float64
+	Divergences  int
+	Score        SignalScore
+	QuoteVolume  float64 // Quote volume over the last 24h of candles
+	DivergenceID string  // Divergence the setup trades off
+	ATR          float64 // Current ATR for volatility sizing
+	ZoneEdge     float64 // Resistance edge for a limit entry (0 = enter at market)
+	ZoneBot      float64 // Nearest resistance zone (scale-in retests, 0 = none)
+	ZoneTop      float64
+}
+
+// rankValue returns the value a candidate is ranked on
+func (c SignalCandidate) rankValue(by string) float64 {
+	switch by {
+	case RANK_BY_RISK_REWARD:
+		return c.RiskReward
+	case RANK_BY_VOLUME:
+		return c.QuoteVolume
+	default:
+		return c.Score.Total
+	}
+}
+
+// RankCandidates orders candidates best first by the given criterion
+// (ties broken by score)
+func RankCandidates(candidates []SignalCandidate, by string) {
+	for i := 0; i < len(candidates)-1; i++ {
+		for j := i + 1; j < len(candidates); j++ {
+			a, b := candidates[i].rankValue(by), candidates[j].rankValue(by)
+			if b > a || (b == a && candidates[j].Score.Total > candidates[i].Score.Total) {
+				candidates[i], candidates[j] = candidates[j], candidates[i]
+			}
+		}
+	}
+}
+
+// recentQuoteVolume sums quote volume over the window ending at the last candle
+func recentQuoteVolume(candles []Candle, window time.Duration) float64 {
+	if len(candles) == 0 {
+		return 0
+	}
+	cutoff := candles[len(candles)-1].OpenTime.Add(-window)
+
+	total := 0.0
+	for i := len(candles) - 1; i >= 0 && candles[i].OpenTime.After(cutoff); i-- {
+		total += candles[i].QuoteAssetVolume
+	}
+	return total
+}
+
+// evaluateCandidate runs the full analysis for a symbol with a scan signal and
+// returns its trade setup, or nil if the setup fails the entry filters
+func (mp *MultiPaperTradingEngine) evaluateCandidate(result MultiSymbolResult) *SignalCandidate {
+	engine := NewOptimizedEngine(result.Symbol, mp.Interval, mp.Limit)
+	if err := engine.FetchData(); err != nil {
+		return nil
+	}
+
+	engine.CalculateIndicators()
+	engine.FindDivergences()
+	engine.IdentifySupportResistance()
+
+	if len(engine.Candles) == 0 || len(engine.RSI) == 0 {
+		return nil
+	}
+
+	// Each divergence may only produce one trade
+	divergence := latestRecentDivergence(engine.Divergences, SIGNAL_SCORE_CONFIG.RecencyHours)
+	if divergence == nil {
+		return nil
+	}
+	if mp.Cooldowns.DivergenceUsed(result.Symbol, divergence.ID()) {
+		if VERBOSE_MODE {
+			fmt.Printf("   ⏸️  [%s] Divergence %s already traded - waiting for a new one\n",
+				result.Symbol, divergence.ID())
+		}
+		return nil
+	}
+
+	currentPrice := engine.LastPrice()
+	currentRSI := engine.RSI[len(engine.RSI)-1]
+
+	nearestResistance, nearestSupport := engine.nearestZones(currentPrice)
+
+	entry := currentPrice
+	stopLoss, slMethod := engine.selectStopLoss("SHORT", entry, nearestResistance)
+	if VERBOSE_MODE {
+		fmt.Printf("   🎯 [%s] Using %s SL: $%.4f (+%.2f%%)\n",
+			result.Symbol, slMethod, stopLoss, (stopLoss-entry)/entry*100)
+	}
+
+	takeProfit, tpMethod := engine.selectTakeProfit("SHORT", entry, stopLoss, nearestSupport)
+	if VERBOSE_MODE {
+		fmt.Printf("   🎯 [%s] Using %s TP: $%.4f (-%.2f%%)\n",
+			result.Symbol, tpMethod, takeProfit, (entry-takeProfit)/entry*100)
+	}
+
+	// CRITICAL FIX: Ensure SL is always ABOVE entry for SHORT
+	if stopLoss <= entry {
+		stopLoss = entry * (1 + STOP_LOSS_PERCENT/100)
+		if VERBOSE_MODE {
+			fmt.Printf("   ⚠️  [%s] WARNING: SL was at/below entry! Adjusted to $%.4f (+%.2f%%)\n",
+				result.Symbol, stopLoss, STOP_LOSS_PERCENT)
+		}
+	}
+
+	// CRITICAL FIX: Ensure TP is always BELOW entry for SHORT
+	if takeProfit >= entry {
+		takeProfit = entry * (1 - TAKE_PROFIT_PERCENT/100)
+		tpMethod = TP_METHOD_FIXED
+		if VERBOSE_MODE {
+			fmt.Printf("   ⚠️  [%s] WARNING: TP was at/above entry! Adjusted to $%.4f (-%.2f%%)\n",
+				result.Symbol, takeProfit, TAKE_PROFIT_PERCENT)
+		}
+	}
+
+	risk := stopLoss - entry
+	reward := entry - takeProfit
+	rr := reward / risk
+	score := engine.ScoreShortSignal(rr, nearestResistance)
+
+	if !score.MeetsMinimum() {
+		if VERBOSE_MODE {
+			fmt.Printf("   ⏸️  [%s] Score %.0f below minimum %.0f\n", result.Symbol, score.Total, MIN_SIGNAL_SCORE)
+		}
+		return nil
+	}
+
+	if rr < RISK_REWARD_RATIO || currentRSI <= 70 ||
+		!engine.regimeAllows(STRATEGY_DIVERGENCE_FADE) || !engine.confirmShortEntry() {
+		return nil
+	}
+
+	candles := engine.RawCandles
+	if len(candles) == 0 {
+		candles = engine.Candles
+	}
+
+	// Rest a limit entry at the resistance bottom when it's close and inside the stop
+	zoneEdge, zoneBot, zoneTop := 0.0, 0.0, 0.0
+	if nearestResistance != nil {
+		zoneBot, zoneTop = nearestResistance.ZoneBot, nearestResistance.ZoneTop
+	}
+	if nearestResistance != nil && nearestResistance.ZoneBot > entry && nearestResistance.ZoneBot < stopLoss &&
+		(nearestResistance.ZoneBot-entry)/entry*100 <= LIMIT_ENTRY_MAX_DISTANCE_PERCENT {
+		zoneEdge = nearestResistance.ZoneBot
+	}
+
+	return &SignalCandidate{
+		Symbol:       result.Symbol,
+		Entry:        entry,
+		StopLoss:     stopLoss,
+		TakeProfit:   takeProfit,
+		TPMethod:     tpMethod,
+		RiskReward:   rr,
+		RSI:          currentRSI,
+		Divergences:  result.Divergences,
+		Score:        score,
+		QuoteVolume:  recentQuoteVolume(candles, 24*time.Hour),
+		DivergenceID: divergence.ID(),
+		ATR:          lastValue(engine.ATR),
+		ZoneEdge:     zoneEdge,
+		ZoneBot:      zoneBot,
+		ZoneTop:      zoneTop,
+	}
+}
+
+// Replacement is an open trade to close once a stronger entry fills
+type Replacement struct {
+	Symbol string
+	Score  float64
+	Price  float64 // Exit reference price
+}
+
+// weakestPosition returns the open trade with the lowest signal score, leaving
+// out trades a resting limit entry is already set to replace
+func (mp *MultiPaperTradingEngine) weakestPosition() *PaperTrade {
+	var weakest *PaperTrade
+	for _, trade := range mp.ActiveTrades {
+		if mp.replacedByPending(trade.Symbol) {
+			continue
+		}
+		if weakest == nil || trade.SignalScore < weakest.SignalScore {
+			weakest = trade
+		}
+	}
+	return weakest
+}
+
+// replacedByPending reports whether a resting limit entry will close symbol's
+// trade when it fills (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) replacedByPending(symbol string) bool {
+	for _, pending := range mp.PendingEntries {
+		if pending.Replaces != nil && pending.Replaces.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// replacementFor picks the weakest open trade if the candidate outscores it by
+// at least REPLACE_MIN_SCORE_MARGIN. Nothing is closed here: the candidate
+// still has to pass every entry gate. The decision is printed either way.
+// (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) replacementFor(candidate SignalCandidate, currentPrices map[string]float64) *Replacement {
+	weakest := mp.weakestPosition()
+	if weakest == nil {
+		return nil
+	}
+
+	margin := candidate.Score.Total - weakest.SignalScore
+	if margin < REPLACE_MIN_SCORE_MARGIN {
+		fmt.Printf("   ⏸️  [%s] Score %.0f not enough to replace %s (%.0f, need +%.0f)\n",
+			candidate.Symbol, candidate.Score.Total, weakest.Symbol, weakest.SignalScore, REPLACE_MIN_SCORE_MARGIN)
+		return nil
+	}
+
+	exitPrice, exists := currentPrices[weakest.Symbol]
+	if !exists {
+		fmt.Printf("   ⚠️  [%s] No price for %s - replacement skipped\n", candidate.Symbol, weakest.Symbol)
+		return nil
+	}
+
+	fmt.Printf("   🔄 [%s] Score %.0f can replace %s (%.0f, +%.0f) if the entry goes through\n",
+		candidate.Symbol, candidate.Score.Total, weakest.Symbol, weakest.SignalScore, margin)
+	return &Replacement{Symbol: weakest.Symbol, Score: weakest.SignalScore, Price: exitPrice}
+}
+
+// closeReplaced closes the trade a new entry replaces, once that entry has
+// filled (caller holds mp.mutex)
+func (mp *MultiPaperTradingEngine) closeReplaced(replace *Replacement, symbol string, score float64) {
+	if replace == nil {
+		return
+	}
+	if _, open := mp.ActiveTrades[replace.Symbol]; !open {
+		return
+	}
+
+	fmt.Printf("\n🔄 REPLACING %s (score %.0f) with %s (score %.0f, +%.0f)\n",
+		replace.Symbol, replace.Score, symbol, score, score-replace.Score)
+	mp.closeTradeInternal(replace.Symbol, replace.Price, "REPLACED")
+}
+
+// allocateSlots ranks the cycle's candidates and opens the best ones in the
+// free slots, replacing weak positions when enabled. A replaced position is
+// only closed after its replacement fills, and its slot and notional count as
+// free for the replacement's gates. Trades that would breach the correlation
+// cluster or net exposure caps are downsized or rejected. Returns trades opened.
+func (mp *MultiPaperTradingEngine) allocateSlots(candidates []SignalCandidate, currentPrices map[string]float64,
+	correlations *CorrelationMatrix) int {
+	RankCandidates(candidates, RANK_SIGNALS_BY)
+
+	if len(candidates) > 0 && VERBOSE_MODE {
+		fmt.Printf("\n📋 %d candidate(s) ranked by %s:\n", len(candidates), RANK_SIGNALS_BY)
+		for i, c := range candidates {
+			fmt.Printf("   %d. %s - score %.0f, R/R %.2f, 24h vol $%.0f\n",
+				i+1, c.Symbol, c.Score.Total, c.RiskReward, c.QuoteVolume)
+		}
+	}
+
+	opened := 0
+	for _, c := range candidates {
+		mp.mutex.Lock()
+		used := mp.slotsUsed()
+		var replace *Replacement
+		if used >= mp.MaxPositions && REPLACE_WEAK_POSITIONS {
+			replace = mp.replacementFor(c, currentPrices)
+		}
+		mp.mutex.Unlock()
+
+		if used >= mp.MaxPositions && replace == nil {
+			if VERBOSE_MODE {
+				fmt.Printf("   ⏸️  [%s] No free slot (%d/%d)\n", c.Symbol, used, mp.MaxPositions)
+			}
+			continue
+		}
+		exclude := ""
+		if replace != nil {
+			exclude = replace.Symbol
+		}
+
+		// Size with the configured sizer (fixed = equal share of the starting balance)
+		mp.mutex.Lock()
+		openNotional, openTrades := mp.openExposure(exclude)
+		stats := rollingTradeStats(mp.Trades, KELLY_LOOKBACK)
+		mp.mutex.Unlock()
+
+		equity := mp.Equity(currentPrices)
+		positionSize, sizingNote := SizePosition(mp.Sizer, SizingInput{
+			StartingBalance: mp.StartingBalance,
+			Equity:          equity,
+			OpenNotional:    openNotional,
+			Slots:           mp.MaxPositions,
+			Entry:           c.Entry,
+			StopLoss:        c.StopLoss,
+			ATR:             c.ATR,
+			ScoreFactor:     c.Score.SizeFactor(),
+			Stats:           stats,
+		}, sizingLimitsFor(c.Symbol))
+
+		if positionSize <= 0 {
+			fmt.Printf("   ⏸️  [%s] Skipped: %s\n", c.Symbol, sizingNote)
+			continue
+		}
+		if sizingNote != "" && VERBOSE_MODE {
+			fmt.Printf("   ⚠️  [%s] %s\n", c.Symbol, sizingNote)
+		}
+
+		// Correlation cluster and net directional exposure caps
+		exposureLimits := DefaultExposureLimits()
+		exposureLimits.MinNotional = sizingLimitsFor(c.Symbol).MinNotional
+		positionSize, exposureNote := CheckExposure(c.Symbol, "SHORT", positionSize, equity,
+			openTrades, correlations, exposureLimits)
+		if positionSize <= 0 {
+			fmt.Printf("   ⏸️  [%s] Rejected: %s\n", c.Symbol, exposureNote)
+			continue
+		}
+		if exposureNote != "" {
+			fmt.Printf("   ⚠️  [%s] %s\n", c.Symbol, exposureNote)
+		}
+
+		// Reject symbols whose book can't absorb the size (--depth-fills)
+		if DEPTH_SOURCE != nil {
+			depth, err := DEPTH_SOURCE.CheckDepth(c.Symbol, entryOrderSide("SHORT"), c.Entry, positionSize)
+			if err != nil {
+				fmt.Printf("   ⏸️  [%s] Rejected by depth: %v\n", c.Symbol, err)
+				continue
+			}
+			if VERBOSE_MODE {
+				fmt.Printf("   💧 [%s] Book impact for $%.2f: %.3f%% over %d level(s)\n",
+					c.Symbol, positionSize, depth.SlippagePct, depth.Levels)
+			}
+		}
+
+		fmt.Printf("\n🎯 SIGNAL: %s (RSI: %.2f, Div: %d, R/R: %.2f:1, Score: %.0f)\n",
+			c.Symbol, c.RSI, c.Divergences, c.RiskReward, c.Score.Total)
+
+		// Limit entries need a broker that simulates resting orders
+		if _, simulated := mp.Broker.(OrderMatcher); ENTRY_ORDER_TYPE == ENTRY_ORDER_LIMIT && c.ZoneEdge > 0 && simulated {
+			if mp.PlaceLimitEntry(c, positionSize, replace) {
+				mp.Cooldowns.MarkDivergenceUsed(c.Symbol, c.DivergenceID)
+			}
+			continue
+		}
+
+		if !mp.OpenTrade(c.Symbol, "SHORT", c.Entry, c.StopLoss, c.TakeProfit, c.TPMethod, c.Score.Total, positionSize, replace) {
+			continue
+		}
+		opened++
+
+		mp.mutex.Lock()
+		if trade, exists := mp.ActiveTrades[c.Symbol]; exists {
+			trade.DivergenceID = c.DivergenceID
+			trade.ScaleIn = NewScaleInRule(c.ZoneBot, c.ZoneTop)
+		}
+		mp.mutex.Unlock()
+		mp.Cooldowns.MarkDivergenceUsed(c.Symbol, c.DivergenceID)
+	}
+
+	return opened
+}
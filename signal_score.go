@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ==================== SIGNAL CONFIDENCE SCORE ====================
+
+// Scale position size by the signal score (set from flags)
+var SCORE_SIZING_ENABLED = false
+
+// Score components
+const (
+	SCORE_RSI_DELTA     = "rsi_delta"     // RSI drop between the divergence swings
+	SCORE_DIV_COUNT     = "div_count"     // Number of recent divergences
+	SCORE_DIV_RECENCY   = "div_recency"   // How recently the last divergence completed
+	SCORE_RSI_EXTREMITY = "rsi_extremity" // How far RSI is into overbought
+	SCORE_ZONE_DISTANCE = "zone_distance" // Proximity of the nearest resistance
+	SCORE_ZONE_STRENGTH = "zone_strength" // Pivot count of the nearest resistance
+	SCORE_RISK_REWARD   = "risk_reward"   // R/R of the planned trade
+	SCORE_REGIME        = "regime"        // How well the regime suits the strategy
+)
+
+// SignalScoreConfig holds the component weights and the values each
+// component is normalized against
+type SignalScoreConfig struct {
+	Weights map[string]float64 // Relative weight per component (0 = ignored)
+
+	RecencyHours        float64            // Divergences older than this don't count (default: 72)
+	MaxRSIDelta         float64            // RSI drop that scores full marks (default: 10)
+	MaxDivergences      int                // Divergence count that scores full marks (default: 3)
+	OverboughtRSI       float64            // RSI at which extremity starts scoring (default: 70)
+	MaxRSI              float64            // RSI that scores full marks (default: 90)
+	MaxZoneDistance     float64            // Resistance further than this % scores zero (default: 2.0)
+	MaxZoneStrength     int                // Zone pivot count that scores full marks (default: 5)
+	MaxRiskRewardFactor float64            // R/R of RISK_REWARD_RATIO * factor scores full marks (default: 2)
+	RegimeScores        map[string]float64 // 0-1 per regime
+	StrongThreshold     float64            // Score at/above this is STRONG (default: 70)
+	MediumThreshold     float64            // Score at/above this is MEDIUM (default: 40)
+}
+
+// DefaultSignalScoreConfig returns the default weights and normalization
+func DefaultSignalScoreConfig() SignalScoreConfig {
+	return SignalScoreConfig{
+		Weights: map[string]float64{
+			SCORE_RSI_DELTA:     15,
+			SCORE_DIV_COUNT:     15,
+			SCORE_DIV_RECENCY:   10,
+			SCORE_RSI_EXTREMITY: 15,
+			SCORE_ZONE_DISTANCE: 10,
+			SCORE_ZONE_STRENGTH: 10,
+			SCORE_RISK_REWARD:   15,
+			SCORE_REGIME:        10,
+		},
+		RecencyHours:        72,
+		MaxRSIDelta:         DIVERGENCE_STRENGTH_HIGH,
+		MaxDivergences:      3,
+		OverboughtRSI:       70,
+		MaxRSI:              90,
+		MaxZoneDistance:     2.0,
+		MaxZoneStrength:     5,
+		MaxRiskRewardFactor: 2,
+		RegimeScores: map[string]float64{
+			REGIME_RANGE:           1.0,
+			REGIME_TRENDING_DOWN:   1.0,
+			REGIME_UNKNOWN:         0.5,
+			REGIME_HIGH_VOLATILITY: 0.2,
+			REGIME_TRENDING_UP:     0.0,
+		},
+		StrongThreshold: 70,
+		MediumThreshold: 40,
+	}
+}
+
+// SIGNAL_SCORE_CONFIG is the active scoring configuration
+var SIGNAL_SCORE_CONFIG = DefaultSignalScoreConfig()
+
+// SignalInputs are the raw values a short signal is scored on
+type SignalInputs struct {
+	RecentDivergences int
+	MaxRSIDelta       float64 // Largest StartRSI - EndRSI among recent divergences
+	HoursSinceLast    float64 // Hours since the latest recent divergence completed (-1 if none)
+	CurrentRSI        float64
+	ZoneDistance      float64 // % from price up to the nearest resistance (-1 if none)
+	ZoneStrength      int
+	RiskReward        float64
+	Regime            string
+}
+
+// ScoreComponent is one normalized (0-1) input and its weight
+type ScoreComponent struct {
+	Name   string
+	Value  float64
+	Weight float64
+}
+
+// SignalScore is the weighted 0-100 confidence of a signal
+type SignalScore struct {
+	Total      float64
+	Components []ScoreComponent
+}
+
+// Label maps the score to the WEAK/MEDIUM/STRONG strength label
+func (s SignalScore) Label() string {
+	if s.Total >= SIGNAL_SCORE_CONFIG.StrongThreshold {
+		return "STRONG"
+	}
+	if s.Total >= SIGNAL_SCORE_CONFIG.MediumThreshold {
+		return "MEDIUM"
+	}
+	return "WEAK"
+}
+
+// SizeFactor returns the position size multiplier for the score
+// (SCORE_SIZING_MIN_FACTOR at 0 up to 1.0 at 100)
+func (s SignalScore) SizeFactor() float64 {
+	if !SCORE_SIZING_ENABLED {
+		return 1.0
+	}
+	return SCORE_SIZING_MIN_FACTOR + (1-SCORE_SIZING_MIN_FACTOR)*s.Total/100
+}
+
+// MeetsMinimum reports whether the score passes MIN_SIGNAL_SCORE
+func (s SignalScore) MeetsMinimum() bool {
+	return s.Total >= MIN_SIGNAL_SCORE
+}
+
+// CalculateSignalScore normalizes each input to 0-1 and returns the weighted
+// average scaled to 0-100
+func CalculateSignalScore(in SignalInputs, config SignalScoreConfig) SignalScore {
+	values := map[string]float64{
+		SCORE_RSI_DELTA:     scoreRatio(in.MaxRSIDelta, config.MaxRSIDelta),
+		SCORE_DIV_COUNT:     scoreRatio(float64(in.RecentDivergences), float64(config.MaxDivergences)),
+		SCORE_RSI_EXTREMITY: scoreRatio(in.CurrentRSI-config.OverboughtRSI, config.MaxRSI-config.OverboughtRSI),
+		SCORE_ZONE_STRENGTH: scoreRatio(float64(in.ZoneStrength), float64(config.MaxZoneStrength)),
+		SCORE_RISK_REWARD:   scoreRatio(in.RiskReward, RISK_REWARD_RATIO*config.MaxRiskRewardFactor),
+		SCORE_REGIME:        config.RegimeScores[in.Regime],
+	}
+	if in.HoursSinceLast >= 0 {
+		values[SCORE_DIV_RECENCY] = 1 - scoreRatio(in.HoursSinceLast, config.RecencyHours)
+	}
+	if in.ZoneDistance >= 0 {
+		values[SCORE_ZONE_DISTANCE] = 1 - scoreRatio(in.ZoneDistance, config.MaxZoneDistance)
+	}
+
+	// Fixed order so breakdowns print consistently
+	names := []string{SCORE_RSI_DELTA, SCORE_DIV_COUNT, SCORE_DIV_RECENCY, SCORE_RSI_EXTREMITY,
+		SCORE_ZONE_DISTANCE, SCORE_ZONE_STRENGTH, SCORE_RISK_REWARD, SCORE_REGIME}
+
+	var score SignalScore
+	totalWeight, weighted := 0.0, 0.0
+	for _, name := range names {
+		weight := config.Weights[name]
+		if weight <= 0 {
+			continue
+		}
+		score.Components = append(score.Components, ScoreComponent{Name: name, Value: values[name], Weight: weight})
+		totalWeight += weight
+		weighted += weight * values[name]
+	}
+	if totalWeight > 0 {
+		score.Total = weighted / totalWeight * 100
+	}
+
+	return score
+}
+
+// scoreRatio returns value/full clamped to 0-1
+func scoreRatio(value, full float64) float64 {
+	if full <= 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, value/full))
+}
+
+// recentDivergenceStats counts divergences that completed within the window
+// and returns the largest RSI drop and hours since the latest one (-1 if none)
+func recentDivergenceStats(divergences []BearishDivergence, windowHours float64) (int, float64, float64) {
+	count := 0
+	maxDelta := 0.0
+	latest := -1.0
+
+	for _, div := range divergences {
+		divTime, err := time.Parse("2006-01-02 15:04", div.EndTime)
+		if err != nil {
+			continue
+		}
+		hoursSince := time.Since(divTime).Hours()
+		if hoursSince >= windowHours {
+			continue
+		}
+
+		count++
+		maxDelta = math.Max(maxDelta, div.StartRSI-div.EndRSI)
+		if latest < 0 || hoursSince < latest {
+			latest = math.Max(0, hoursSince)
+		}
+	}
+
+	return count, maxDelta, latest
+}
+
+// ScoreShortSignal scores a divergence-fade short at the current price with
+// the planned R/R and the resistance the stop is anchored to
+func (e *TradingEngine) ScoreShortSignal(riskReward float64, nearestResistance *SRZone) SignalScore {
+	config := SIGNAL_SCORE_CONFIG
+	in := SignalInputs{
+		ZoneDistance: -1,
+		RiskReward:   riskReward,
+		Regime:       e.CurrentRegime(),
+	}
+	in.RecentDivergences, in.MaxRSIDelta, in.HoursSinceLast = recentDivergenceStats(e.Divergences, config.RecencyHours)
+
+	if len(e.RSI) > 0 {
+		in.CurrentRSI = e.RSI[len(e.RSI)-1]
+	}
+	if price := e.LastPrice(); nearestResistance != nil && price > 0 {
+		in.ZoneDistance = math.Max(0, nearestResistance.ZoneBot-price) / price * 100
+		in.ZoneStrength = nearestResistance.Strength
+	}
+
+	return CalculateSignalScore(in, config)
+}
+
+// printSignalScore displays the score and its weighted components
+func printSignalScore(score SignalScore) {
+	fmt.Printf("🏅 Signal Score: %.0f/100 (%s)\n", score.Total, score.Label())
+	for _, c := range score.Components {
+		fmt.Printf("   • %-14s %.2f (weight %.0f)\n", c.Name, c.Value, c.Weight)
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ==================== SMART MONEY STRUCTURES ====================
+
+// Smart-money zone kinds
+const (
+	SMC_FAIR_VALUE_GAP = "fvg"
+	SMC_ORDER_BLOCK    = "order_block"
+)
+
+// Smart-money zone statuses
+const (
+	SMC_STATUS_UNFILLED    = "unfilled"    // FVG not yet traded into / OB not yet revisited
+	SMC_STATUS_PARTIAL     = "partial"     // FVG partially filled
+	SMC_STATUS_FILLED      = "filled"      // FVG completely filled
+	SMC_STATUS_MITIGATED   = "mitigated"   // OB revisited but held
+	SMC_STATUS_INVALIDATED = "invalidated" // OB closed through
+)
+
+// SMCZone is a fair value gap or order block. The embedded SRZone carries
+// the price band so it can be used anywhere an S/R zone is.
+type SMCZone struct {
+	SRZone
+	Kind         string  // SMC_FAIR_VALUE_GAP or SMC_ORDER_BLOCK
+	Bullish      bool    // Bullish FVG/OB sits below price and acts as support
+	CreatedIndex int     // Candle index where the zone was confirmed
+	Status       string  // One of the SMC_STATUS_* values
+	FillPercent  float64 // 0-100, how much of the zone price has traded through
+	UpdatedAt    time.Time
+}
+
+// IsActive reports whether the zone can still act as a target or stop anchor
+func (z SMCZone) IsActive() bool {
+	return z.Status != SMC_STATUS_FILLED && z.Status != SMC_STATUS_INVALIDATED
+}
+
+// UnfilledEdge returns the near edge of the part of the zone price hasn't
+// traded through yet (the top of a bullish zone when untouched)
+func (z SMCZone) UnfilledEdge() float64 {
+	filled := (z.ZoneTop - z.ZoneBot) * z.FillPercent / 100
+	if z.Bullish {
+		return z.ZoneTop - filled
+	}
+	return z.ZoneBot + filled
+}
+
+// newSMCZone builds a zone from its price band
+func newSMCZone(kind string, bullish bool, top, bot float64, index int, c Candle, atr float64) SMCZone {
+	zoneType := "resistance"
+	if bullish {
+		zoneType = "support"
+	}
+	return SMCZone{
+		SRZone: SRZone{
+			Level:      (top + bot) / 2,
+			ZoneTop:    top,
+			ZoneBot:    bot,
+			Strength:   1,
+			Type:       zoneType,
+			IsBullish:  bullish,
+			FirstTouch: c.OpenTime,
+			LastTouch:  c.OpenTime,
+			ZoneRange:  top - bot,
+			AvgATR:     atr,
+		},
+		Kind:         kind,
+		Bullish:      bullish,
+		CreatedIndex: index,
+		Status:       SMC_STATUS_UNFILLED,
+		UpdatedAt:    c.OpenTime,
+	}
+}
+
+// detectFairValueGaps finds three-candle imbalances: a bullish FVG when the
+// third candle's low is above the first candle's high (and vice versa)
+func detectFairValueGaps(candles []Candle, atr []float64, minGapATR float64) []SMCZone {
+	var zones []SMCZone
+
+	for i := 2; i < len(candles); i++ {
+		first, third := candles[i-2], candles[i]
+		minGap := 0.0
+		if atr[i] > 0 {
+			minGap = atr[i] * minGapATR
+		}
+
+		if third.Low > first.High && third.Low-first.High >= minGap {
+			zones = append(zones, newSMCZone(SMC_FAIR_VALUE_GAP, true, third.Low, first.High, i, candles[i-1], atr[i]))
+		}
+		if third.High < first.Low && first.Low-third.High >= minGap {
+			zones = append(zones, newSMCZone(SMC_FAIR_VALUE_GAP, false, first.Low, third.High, i, candles[i-1], atr[i]))
+		}
+	}
+
+	return zones
+}
+
+// detectOrderBlocks finds the last opposite-colored candle before a
+// displacement candle (body >= displacementATR * ATR) that breaks its extreme
+func detectOrderBlocks(candles []Candle, atr []float64, displacementATR float64) []SMCZone {
+	var zones []SMCZone
+
+	for i := 1; i < len(candles); i++ {
+		if atr[i] <= 0 {
+			continue
+		}
+		c := candles[i]
+		body := candleBody(c)
+		if body < atr[i]*displacementATR {
+			continue
+		}
+
+		bullishMove := c.Close > c.Open
+		for j := i - 1; j >= 0 && j >= i-3; j-- {
+			ob := candles[j]
+			obBullish := ob.Close > ob.Open
+			if obBullish == bullishMove {
+				continue // Not the opposite candle yet
+			}
+			if bullishMove && c.Close > ob.High {
+				zones = append(zones, newSMCZone(SMC_ORDER_BLOCK, true, ob.High, ob.Low, i, ob, atr[i]))
+			} else if !bullishMove && c.Close < ob.Low {
+				zones = append(zones, newSMCZone(SMC_ORDER_BLOCK, false, ob.High, ob.Low, i, ob, atr[i]))
+			}
+			break
+		}
+	}
+
+	return zones
+}
+
+// updateSMCZone advances a zone's fill/mitigation status with one new candle
+func updateSMCZone(zone *SMCZone, c Candle) {
+	if !zone.IsActive() {
+		return
+	}
+	height := zone.ZoneTop - zone.ZoneBot
+	if height <= 0 {
+		return
+	}
+
+	// How deep price has traded into the zone from the side it approaches
+	var depth float64
+	if zone.Bullish {
+		depth = (zone.ZoneTop - c.Low) / height * 100
+	} else {
+		depth = (c.High - zone.ZoneBot) / height * 100
+	}
+	if depth <= 0 {
+		return
+	}
+	depth = math.Min(100, depth)
+	if depth > zone.FillPercent {
+		zone.FillPercent = depth
+	}
+	zone.LastTouch = c.OpenTime
+	zone.UpdatedAt = c.OpenTime
+
+	switch zone.Kind {
+	case SMC_FAIR_VALUE_GAP:
+		if zone.FillPercent >= 100 {
+			zone.Status = SMC_STATUS_FILLED
+		} else {
+			zone.Status = SMC_STATUS_PARTIAL
+		}
+	case SMC_ORDER_BLOCK:
+		closedThrough := (zone.Bullish && c.Close < zone.ZoneBot) || (!zone.Bullish && c.Close > zone.ZoneTop)
+		if closedThrough {
+			zone.Status = SMC_STATUS_INVALIDATED
+		} else {
+			zone.Status = SMC_STATUS_MITIGATED
+		}
+	}
+}
+
+// findSmartMoneyZones detects FVGs and order blocks within the lookback and
+// replays the following candles to set their current status
+func findSmartMoneyZones(candles []Candle, atrLength int) []SMCZone {
+	atr := calcATR(candles, atrLength)
+
+	start := len(candles) - SMC_LOOKBACK
+	if start < 0 {
+		start = 0
+	}
+
+	detected := append(detectFairValueGaps(candles, atr, FVG_MIN_GAP_ATR),
+		detectOrderBlocks(candles, atr, OB_DISPLACEMENT_ATR)...)
+
+	var zones []SMCZone
+	for _, zone := range detected {
+		if zone.CreatedIndex < start {
+			continue
+		}
+		for i := zone.CreatedIndex + 1; i < len(candles); i++ {
+			updateSMCZone(&zone, candles[i])
+		}
+		zones = append(zones, zone)
+	}
+
+	return zones
+}
+
+// IdentifySmartMoney detects fair value gaps and order blocks
+func (e *TradingEngine) IdentifySmartMoney() {
+	if len(e.Candles) == 0 {
+		return
+	}
+
+	e.SMCZones = findSmartMoneyZones(e.Candles, e.SRConfig.ATRLength)
+
+	if !SHOW_SR_ZONES {
+		return
+	}
+
+	activeFVG, activeOB := 0, 0
+	for _, zone := range e.SMCZones {
+		if !zone.IsActive() {
+			continue
+		}
+		if zone.Kind == SMC_FAIR_VALUE_GAP {
+			activeFVG++
+		} else {
+			activeOB++
+		}
+	}
+	fmt.Printf("   💧 Smart money: %d open FVG(s), %d active order block(s)\n", activeFVG, activeOB)
+}
+
+// nearestOrderBlock returns the closest active order block beyond entry on the
+// stop side (above for SHORT, below for LONG) within maxDistancePercent
+func (e *TradingEngine) nearestOrderBlock(side string, entry, maxDistancePercent float64) *SMCZone {
+	var best *SMCZone
+	bestDistance := math.MaxFloat64
+
+	for i := range e.SMCZones {
+		zone := &e.SMCZones[i]
+		if zone.Kind != SMC_ORDER_BLOCK || !zone.IsActive() {
+			continue
+		}
+
+		var distance float64
+		if side == "SHORT" {
+			if zone.Bullish || zone.ZoneTop <= entry {
+				continue
+			}
+			distance = math.Max(0, zone.ZoneBot-entry)
+		} else {
+			if !zone.Bullish || zone.ZoneBot >= entry {
+				continue
+			}
+			distance = math.Max(0, entry-zone.ZoneTop)
+		}
+
+		if distance/entry*100 > maxDistancePercent {
+			continue
+		}
+		if distance < bestDistance {
+			bestDistance = distance
+			best = zone
+		}
+	}
+
+	return best
+}
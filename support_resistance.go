@@ -251,17 +251,23 @@ func mergeZones(zones []SRZone, alignZones bool) []SRZone {
 // ==================== DYNAMIC ZONE COLOR UPDATE ====================
 
 // updateZonePolarityByPrice updates whether zones are acting as support or resistance
-// based on current price position (mimics the _color function)
+// based on current price position (mimics the _color function).
+// Volume profile zones keep their Type ("vpoc", "vah", ...) and only flip IsBullish.
 func updateZonePolarityByPrice(zones []SRZone, currentPrice float64) []SRZone {
 	for i := range zones {
+		isPivotZone := zones[i].Type == "support" || zones[i].Type == "resistance"
 		if currentPrice > zones[i].ZoneTop {
 			// Price is above zone - it's now support
 			zones[i].IsBullish = true
-			zones[i].Type = "support"
+			if isPivotZone {
+				zones[i].Type = "support"
+			}
 		} else if currentPrice < zones[i].ZoneBot {
 			// Price is below zone - it's now resistance
 			zones[i].IsBullish = false
-			zones[i].Type = "resistance"
+			if isPivotZone {
+				zones[i].Type = "resistance"
+			}
 		}
 		// If price is within zone, keep existing polarity
 	}
@@ -270,8 +276,24 @@ func updateZonePolarityByPrice(zones []SRZone, currentPrice float64) []SRZone {
 
 // ==================== MAIN S/R DETECTION FUNCTION ====================
 
-// findAdvancedSupportResistance implements the TradingView indicator logic
-func findAdvancedSupportResistance(candles []Candle, config SRConfig) []SRZone {
+// srVolumeProfile builds the volume profile for the S/R config (nil when disabled)
+func srVolumeProfile(candles []Candle, config SRConfig) *VolumeProfile {
+	if !config.UseVolumeProfile {
+		return nil
+	}
+	vpConfig := DefaultVolumeProfileConfig()
+	if config.VolumeProfileWindow > 0 {
+		vpConfig.Window = config.VolumeProfileWindow
+	}
+	if config.VolumeProfileBins > 0 {
+		vpConfig.Bins = config.VolumeProfileBins
+	}
+	return calcVolumeProfile(candles, vpConfig)
+}
+
+// findAdvancedSupportResistance implements the TradingView indicator logic.
+// profile (may be nil) adds volume profile zones.
+func findAdvancedSupportResistance(candles []Candle, config SRConfig, profile *VolumeProfile) []SRZone {
 	// Step 1: Calculate ATR
 	atr := calcATR(candles, config.ATRLength)
 
@@ -322,6 +344,15 @@ func findAdvancedSupportResistance(candles []Candle, config SRConfig) []SRZone {
 		allZones = append(allZones, zone)
 	}
 
+	// Step 4b: Add volume profile zones (POC, value area, HVN/LVN)
+	if profile != nil {
+		avgATR := 0.0
+		if len(atr) > 0 && atr[len(atr)-1] > 0 {
+			avgATR = atr[len(atr)-1]
+		}
+		allZones = append(allZones, volumeProfileZones(profile, avgATR)...)
+	}
+
 	// Step 5: Merge overlapping zones (zone alignment)
 	mergedZones := mergeZones(allZones, config.AlignZones)
 
@@ -400,6 +431,14 @@ type SRConfig struct {
 	AlignZones     bool    // Enable zone merging (default: true)
 	MinStrength    int     // Minimum touches for significance (default: 1)
 	MaxZones       int     // Maximum zones to return (default: 20)
+
+	// Volume profile zones
+	UseVolumeProfile    bool // Merge volume profile zones with pivot zones (default: true)
+	VolumeProfileWindow int  // Candles in the profile window (default: 300)
+	VolumeProfileBins   int  // Price buckets in the profile (default: 50)
+
+	// Diagonal structure
+	UseTrendlines bool // Add unbroken trendlines/channels as zones (default: true)
 }
 
 // DefaultSRConfig returns configuration matching the TradingView indicator
@@ -413,5 +452,11 @@ func DefaultSRConfig() SRConfig {
 		AlignZones:     true,
 		MinStrength:    1,
 		MaxZones:       20,
+
+		UseVolumeProfile:    true,
+		VolumeProfileWindow: 300,
+		VolumeProfileBins:   50,
+
+		UseTrendlines: true,
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,39 @@ type TradeLogger struct {
 	writer   *csv.Writer
 }
 
+// tradeLogHeaders is the CSV column layout written by LogTrade
+var tradeLogHeaders = []string{
+	"Trade_ID",
+	"Symbol",
+	"Interval",
+	"Side",
+	"Entry_Time",
+	"Entry_Price",
+	"Exit_Time",
+	"Exit_Price",
+	"Stop_Loss",
+	"Take_Profit",
+	"Position_Size",
+	"Status",
+	"Profit_Loss",
+	"Profit_Loss_Pct",
+	"Risk_Reward",
+	"Highest_Price",
+	"Lowest_Price",
+	"Max_Profit",
+	"Max_Profit_Pct",
+	"Give_Back",
+	"Give_Back_Pct",
+	"Duration_Minutes",
+	"Logged_At",
+	"TP_Method",
+	"Signal_Score",
+	"Entry_Slippage_Pct",
+	"Exit_Slippage_Pct",
+	"Fill_Legs",
+	"Exit_Ledger",
+}
+
 // NewTradeLogger creates a logger for single-symbol paper trading
 // Appends trades to a single file per symbol (e.g., trades_BTCUSDT.csv)
 func NewTradeLogger(symbol string) (*TradeLogger, error) {
@@ -27,64 +61,7 @@ func NewTradeLogger(symbol string) (*TradeLogger, error) {
 	// Single file per symbol - APPEND mode
 	filename := filepath.Join(logsDir, fmt.Sprintf("trades_%s.csv", symbol))
 
-	// Check if file exists to determine if we need to write headers
-	fileExists := false
-	if _, err := os.Stat(filename); err == nil {
-		fileExists = true
-	}
-
-	// Open file in append mode (creates if doesn't exist)
-	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open CSV file: %w", err)
-	}
-
-	writer := csv.NewWriter(file)
-
-	// Write headers only if file is new
-	if !fileExists {
-		headers := []string{
-			"Trade_ID",
-			"Symbol",
-			"Interval",
-			"Side",
-			"Entry_Time",
-			"Entry_Price",
-			"Exit_Time",
-			"Exit_Price",
-			"Stop_Loss",
-			"Take_Profit",
-			"Position_Size",
-			"Status",
-			"Profit_Loss",
-			"Profit_Loss_Pct",
-			"Risk_Reward",
-			"Highest_Price",
-			"Lowest_Price",
-			"Max_Profit",
-			"Max_Profit_Pct",
-			"Give_Back",
-			"Give_Back_Pct",
-			"Duration_Minutes",
-			"Logged_At",
-		}
-
-		if err := writer.Write(headers); err != nil {
-			file.Close()
-			return nil, fmt.Errorf("failed to write CSV headers: %w", err)
-		}
-
-		writer.Flush()
-		fmt.Printf("📝 Created new trade log: %s\n", filename)
-	} else {
-		fmt.Printf("📝 Appending to existing trade log: %s\n", filename)
-	}
-
-	return &TradeLogger{
-		filename: filename,
-		file:     file,
-		writer:   writer,
-	}, nil
+	return openTradeLog(filename, "trade log")
 }
 
 // NewMultiTradeLogger creates a logger for multi-symbol paper trading
@@ -98,12 +75,28 @@ func NewMultiTradeLogger() (*TradeLogger, error) {
 	// Single file for ALL multi-symbol trades - APPEND mode
 	filename := filepath.Join(logsDir, "trades_all_symbols.csv")
 
+	return openTradeLog(filename, "multi-symbol trade log")
+}
+
+// openTradeLog opens a CSV log in append mode, writing headers for new files.
+// A file written with an older column layout is renamed (kept for reference)
+// so columns never shift under existing rows.
+func openTradeLog(filename, label string) (*TradeLogger, error) {
 	// Check if file exists to determine if we need to write headers
 	fileExists := false
-	if _, err := os.Stat(filename); err == nil {
+	if info, err := os.Stat(filename); err == nil && info.Size() > 0 {
 		fileExists = true
 	}
 
+	if fileExists && !tradeLogHeaderMatches(filename) {
+		rotated := strings.TrimSuffix(filename, ".csv") + "_" + time.Now().Format("20060102_150405") + ".csv"
+		if err := os.Rename(filename, rotated); err != nil {
+			return nil, fmt.Errorf("failed to rotate outdated trade log: %w", err)
+		}
+		fmt.Printf("📝 Trade log columns changed - previous log moved to %s\n", rotated)
+		fileExists = false
+	}
+
 	// Open file in append mode (creates if doesn't exist)
 	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -114,41 +107,15 @@ func NewMultiTradeLogger() (*TradeLogger, error) {
 
 	// Write headers only if file is new
 	if !fileExists {
-		headers := []string{
-			"Trade_ID",
-			"Symbol",
-			"Interval",
-			"Side",
-			"Entry_Time",
-			"Entry_Price",
-			"Exit_Time",
-			"Exit_Price",
-			"Stop_Loss",
-			"Take_Profit",
-			"Position_Size",
-			"Status",
-			"Profit_Loss",
-			"Profit_Loss_Pct",
-			"Risk_Reward",
-			"Highest_Price",
-			"Lowest_Price",
-			"Max_Profit",
-			"Max_Profit_Pct",
-			"Give_Back",
-			"Give_Back_Pct",
-			"Duration_Minutes",
-			"Logged_At",
-		}
-
-		if err := writer.Write(headers); err != nil {
+		if err := writer.Write(tradeLogHeaders); err != nil {
 			file.Close()
 			return nil, fmt.Errorf("failed to write CSV headers: %w", err)
 		}
 
 		writer.Flush()
-		fmt.Printf("📝 Created new multi-symbol trade log: %s\n", filename)
+		fmt.Printf("📝 Created new %s: %s\n", label, filename)
 	} else {
-		fmt.Printf("📝 Appending to existing multi-symbol trade log: %s\n", filename)
+		fmt.Printf("📝 Appending to existing %s: %s\n", label, filename)
 	}
 
 	return &TradeLogger{
@@ -158,6 +125,22 @@ func NewMultiTradeLogger() (*TradeLogger, error) {
 	}, nil
 }
 
+// tradeLogHeaderMatches reports whether an existing log uses the current
+// columns (an unreadable file counts as a match)
+func tradeLogHeaderMatches(filename string) bool {
+	file, err := os.Open(filename)
+	if err != nil {
+		return true
+	}
+	defer file.Close()
+
+	header, err := csv.NewReader(file).Read()
+	if err != nil {
+		return true
+	}
+	return strings.Join(header, ",") == strings.Join(tradeLogHeaders, ",")
+}
+
 // LogTrade writes a completed trade to the CSV file
 func (tl *TradeLogger) LogTrade(trade *PaperTrade) error {
 	if tl == nil || tl.writer == nil {
@@ -194,6 +177,12 @@ func (tl *TradeLogger) LogTrade(trade *PaperTrade) error {
 		fmt.Sprintf("%.2f", giveBackPct),
 		fmt.Sprintf("%.2f", duration),
 		time.Now().Format("2006-01-02 15:04:05"),
+		trade.TPMethod,
+		fmt.Sprintf("%.1f", trade.SignalScore),
+		fmt.Sprintf("%.3f", trade.EntrySlippagePct),
+		fmt.Sprintf("%.3f", trade.ExitSlippagePct),
+		formatFillLegs(trade.Fills),
+		formatExitLedger(trade.PartialExits),
 	}
 
 	if err := tl.writer.Write(record); err != nil {
@@ -210,6 +199,16 @@ func (tl *TradeLogger) LogTrade(trade *PaperTrade) error {
 	return nil
 }
 
+// formatExitLedger renders partial exits for the CSV as "qty@price:profit"
+// joined by ";"
+func formatExitLedger(exits []TradeExit) string {
+	steps := make([]string, len(exits))
+	for i, exit := range exits {
+		steps[i] = fmt.Sprintf("%.8g@%.4f:%+.4f", exit.Quantity, exit.Price, exit.Profit)
+	}
+	return strings.Join(steps, ";")
+}
+
 // Close closes the CSV file
 func (tl *TradeLogger) Close() error {
 	if tl != nil && tl.file != nil {
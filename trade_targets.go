@@ -0,0 +1,148 @@
+package main
+
+import (
+	"math"
+)
+
+// ==================== STOP & TARGET SELECTION ====================
+
+// Methods recorded for how a stop or take-profit was chosen
+const (
+	TP_METHOD_SR_ZONE = "sr_zone"       // Nearest support (TP) / resistance (SL) zone edge
+	TP_METHOD_FIXED   = "fixed_percent" // TAKE_PROFIT_PERCENT / STOP_LOSS_PERCENT from entry
+	TP_METHOD_FIB     = "fib"           // Prefix: "fib_retracement_0.618", "fib_extension_1.272"
+	TP_METHOD_FVG     = "fvg"           // Near edge of an unfilled fair value gap
+
+	SL_METHOD_ORDER_BLOCK = "order_block" // Beyond the nearest active order block
+)
+
+// selectStopLoss places the stop beyond the nearest resistance zone (or the
+// fixed percentage). With SMC_STOPS_ENABLED, an active order block just above
+// entry takes precedence when it lies beyond the zone: the stop goes beyond
+// the block plus an ATR buffer.
+func (e *TradingEngine) selectStopLoss(side string, entry float64, nearestZone *SRZone) (float64, string) {
+	stopLoss, method := entry*(1+STOP_LOSS_PERCENT/100), TP_METHOD_FIXED
+	if side == "LONG" {
+		stopLoss = entry * (1 - STOP_LOSS_PERCENT/100)
+	}
+	if nearestZone != nil {
+		stopLoss, method = nearestZone.ZoneTop, TP_METHOD_SR_ZONE
+		if side == "LONG" {
+			stopLoss = nearestZone.ZoneBot
+		}
+	}
+
+	if !SMC_STOPS_ENABLED {
+		return stopLoss, method
+	}
+
+	block := e.nearestOrderBlock(side, entry, SMC_OB_MAX_DISTANCE_PERCENT)
+	if block == nil {
+		return stopLoss, method
+	}
+	// A block inside the zone would tighten the stop into the level being faded
+	if nearestZone != nil {
+		if side == "LONG" && block.ZoneBot > nearestZone.ZoneBot {
+			return stopLoss, method
+		}
+		if side != "LONG" && block.ZoneTop < nearestZone.ZoneTop {
+			return stopLoss, method
+		}
+	}
+
+	buffer := block.AvgATR * SMC_STOP_BUFFER_ATR
+	if side == "LONG" {
+		return block.ZoneBot - buffer, SL_METHOD_ORDER_BLOCK
+	}
+	return block.ZoneTop + buffer, SL_METHOD_ORDER_BLOCK
+}
+
+// selectTakeProfit picks the take-profit for a trade and reports which method
+// produced it. Fibonacci levels (FIB_TARGETS_ENABLED) and unfilled fair value
+// gaps (SMC_TARGETS_ENABLED) compete with the nearest support zone: the
+// nearest target that still meets RISK_REWARD_RATIO wins. If none qualifies,
+// the support zone or the fixed percentage is used, as before.
+func (e *TradingEngine) selectTakeProfit(side string, entry, stopLoss float64, nearestZone *SRZone) (float64, string) {
+	fallback, fallbackMethod := entry*(1-TAKE_PROFIT_PERCENT/100), TP_METHOD_FIXED
+	if side == "LONG" {
+		fallback = entry * (1 + TAKE_PROFIT_PERCENT/100)
+	}
+	if nearestZone != nil {
+		fallback, fallbackMethod = nearestZone.ZoneBot, TP_METHOD_SR_ZONE
+		if side == "LONG" {
+			fallback = nearestZone.ZoneTop
+		}
+	}
+
+	risk := math.Abs(stopLoss - entry)
+	if risk <= 0 {
+		return fallback, fallbackMethod
+	}
+
+	type target struct {
+		price  float64
+		method string
+	}
+	candidates := []target{{fallback, fallbackMethod}}
+	if FIB_TARGETS_ENABLED {
+		for _, level := range e.FibLevels {
+			candidates = append(candidates, target{level.Price, level.Method()})
+		}
+	}
+	if SMC_TARGETS_ENABLED {
+		for _, zone := range e.SMCZones {
+			if zone.Kind != SMC_FAIR_VALUE_GAP || !zone.IsActive() {
+				continue
+			}
+			// Price fills a gap from the side it approaches: the unfilled
+			// top for a SHORT heading down into a bullish gap, bottom for a LONG
+			if (side == "SHORT" && zone.Bullish) || (side == "LONG" && !zone.Bullish) {
+				candidates = append(candidates, target{zone.UnfilledEdge(), TP_METHOD_FVG})
+			}
+		}
+	}
+	if len(candidates) == 1 {
+		return fallback, fallbackMethod
+	}
+
+	best := target{}
+	bestDistance := math.MaxFloat64
+	for _, c := range candidates {
+		reward := entry - c.price
+		if side == "LONG" {
+			reward = c.price - entry
+		}
+		if reward <= 0 || reward/risk < RISK_REWARD_RATIO {
+			continue
+		}
+		if reward < bestDistance {
+			bestDistance = reward
+			best = c
+		}
+	}
+
+	if best.method == "" {
+		return fallback, fallbackMethod
+	}
+	return best.price, best.method
+}
+
+// nearestZones returns the closest zone above price (resistance) and below
+// price (support), either of which may be nil
+func (e *TradingEngine) nearestZones(price float64) (*SRZone, *SRZone) {
+	var resistance, support *SRZone
+	minDistanceUp, minDistanceDown := math.MaxFloat64, math.MaxFloat64
+
+	for i := range e.SRZones {
+		level := e.SRZones[i].Level
+		if level > price && level-price < minDistanceUp {
+			minDistanceUp = level - price
+			resistance = &e.SRZones[i]
+		} else if level < price && price-level < minDistanceDown {
+			minDistanceDown = price - level
+			support = &e.SRZones[i]
+		}
+	}
+
+	return resistance, support
+}
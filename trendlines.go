@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ==================== TRENDLINES & CHANNELS ====================
+
+// Trendline is a diagonal line through two or more pivots of the same kind
+type Trendline struct {
+	Type       string  // "resistance" (through pivot highs) or "support" (through pivot lows)
+	StartIndex int     // Candle index of the first anchor
+	StartPrice float64 // Line price at StartIndex
+	Slope      float64 // Price change per candle
+	Anchors    []int   // Candle indices of the pivots touching the line
+	Touches    int
+	FirstTouch time.Time
+	LastTouch  time.Time
+	AgeBars    int     // Candles since the first anchor
+	Tolerance  float64 // Max distance from the line that still counts as a touch
+	Score      float64 // Higher = more touches, more recent
+	Broken     bool
+	BreakIndex int // Candle index of the first close beyond the line (if Broken)
+}
+
+// PriceAt projects the line to a candle index
+func (t Trendline) PriceAt(index int) float64 {
+	return t.StartPrice + t.Slope*float64(index-t.StartIndex)
+}
+
+// Channel pairs a trendline with a parallel line through the opposite pivots
+type Channel struct {
+	Upper Trendline
+	Lower Trendline
+	Base  string // "upper" or "lower" - the fitted line; the other is its parallel
+}
+
+// TrendlineBreak is emitted when a candle closes through an unbroken line
+type TrendlineBreak struct {
+	Line      Trendline
+	Index     int
+	Time      time.Time
+	Price     float64 // Close that broke the line
+	Direction string  // "up" (closed above resistance) or "down" (closed below support)
+}
+
+// TrendlineConfig holds trendline detection parameters
+type TrendlineConfig struct {
+	ToleranceATR  float64 // Touch tolerance = ATR * multiplier (default: 0.25)
+	MinTouches    int     // Minimum pivots on a line (default: 2)
+	MinSpacing    int     // Minimum candles between the first two anchors (default: 10)
+	MaxAgeBars    int     // Ignore lines whose first anchor is older than this (default: 500)
+	MaxLines      int     // Maximum lines kept per type (default: 5)
+	BreakLookback int     // Breaks within this many candles are reported as events (default: 5)
+}
+
+// DefaultTrendlineConfig returns the default trendline parameters
+func DefaultTrendlineConfig() TrendlineConfig {
+	return TrendlineConfig{
+		ToleranceATR:  0.25,
+		MinTouches:    2,
+		MinSpacing:    10,
+		MaxAgeBars:    500,
+		MaxLines:      5,
+		BreakLookback: 5,
+	}
+}
+
+// findTrendlines fits lines through every pair of pivots, counts the other
+// pivots that touch each line and drops lines that price closed through
+// between anchors. A close through the line after its last anchor marks it broken.
+func findTrendlines(candles []Candle, pivots []PivotPoint, lineType string, atr []float64, config TrendlineConfig) []Trendline {
+	var candidates []Trendline
+	last := len(candles) - 1
+
+	for a := 0; a < len(pivots)-1; a++ {
+		if last-pivots[a].Index > config.MaxAgeBars {
+			continue
+		}
+
+		for b := a + 1; b < len(pivots); b++ {
+			if pivots[b].Index-pivots[a].Index < config.MinSpacing {
+				continue
+			}
+
+			line := Trendline{
+				Type:       lineType,
+				StartIndex: pivots[a].Index,
+				StartPrice: pivots[a].Price,
+				Slope:      (pivots[b].Price - pivots[a].Price) / float64(pivots[b].Index-pivots[a].Index),
+				Tolerance:  trendlineTolerance(candles, atr, pivots[b].Index, config),
+			}
+
+			// Count touches from the first anchor onwards
+			for k := a; k < len(pivots); k++ {
+				if math.Abs(pivots[k].Price-line.PriceAt(pivots[k].Index)) <= line.Tolerance || k == a || k == b {
+					line.Anchors = append(line.Anchors, pivots[k].Index)
+					if line.FirstTouch.IsZero() {
+						line.FirstTouch = pivots[k].Time
+					}
+					line.LastTouch = pivots[k].Time
+				}
+			}
+			line.Touches = len(line.Anchors)
+			if line.Touches < config.MinTouches {
+				continue
+			}
+
+			// Reject lines price closed through between anchors; flag later breaks
+			lastAnchor := line.Anchors[len(line.Anchors)-1]
+			valid := true
+			for i := line.StartIndex; i <= last; i++ {
+				if !closesThrough(candles[i].Close, line, i) {
+					continue
+				}
+				if i <= lastAnchor {
+					valid = false
+				} else {
+					line.Broken = true
+					line.BreakIndex = i
+				}
+				break
+			}
+			if !valid {
+				continue
+			}
+
+			line.AgeBars = last - line.StartIndex
+			barsSinceTouch := float64(last - lastAnchor)
+			line.Score = float64(line.Touches)*10 +
+				10*math.Max(0, 1-float64(line.AgeBars)/float64(config.MaxAgeBars)) +
+				10*math.Max(0, 1-barsSinceTouch/float64(config.MaxAgeBars))
+
+			candidates = append(candidates, line)
+		}
+	}
+
+	// Sort by score descending
+	for i := 0; i < len(candidates)-1; i++ {
+		for j := i + 1; j < len(candidates); j++ {
+			if candidates[j].Score > candidates[i].Score {
+				candidates[i], candidates[j] = candidates[j], candidates[i]
+			}
+		}
+	}
+
+	// Keep the best line for each anchor set (a 3-touch line also contains
+	// every 2-touch line through its anchors)
+	var lines []Trendline
+	for _, candidate := range candidates {
+		duplicate := false
+		for _, kept := range lines {
+			if sharesAnchorPair(candidate, kept) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		lines = append(lines, candidate)
+		if config.MaxLines > 0 && len(lines) >= config.MaxLines {
+			break
+		}
+	}
+
+	return lines
+}
+
+// trendlineTolerance returns the touch tolerance at a candle (ATR based,
+// falling back to 0.1% of price before ATR is available)
+func trendlineTolerance(candles []Candle, atr []float64, index int, config TrendlineConfig) float64 {
+	if index < len(atr) && atr[index] > 0 {
+		return atr[index] * config.ToleranceATR
+	}
+	return candles[index].Close * 0.001
+}
+
+// closesThrough reports whether a close is beyond the line by more than its tolerance
+func closesThrough(closePrice float64, line Trendline, index int) bool {
+	if line.Type == "resistance" {
+		return closePrice > line.PriceAt(index)+line.Tolerance
+	}
+	return closePrice < line.PriceAt(index)-line.Tolerance
+}
+
+// sharesAnchorPair reports whether two lines have at least two anchors in common
+func sharesAnchorPair(a, b Trendline) bool {
+	shared := 0
+	for _, x := range a.Anchors {
+		for _, y := range b.Anchors {
+			if x == y {
+				shared++
+			}
+		}
+	}
+	return shared >= 2
+}
+
+// findChannels builds a parallel line for each unbroken trendline through the
+// most extreme opposite pivot. The channel counts only if at least two
+// opposite pivots touch the parallel line.
+func findChannels(lines []Trendline, highs, lows []PivotPoint, config TrendlineConfig) []Channel {
+	var channels []Channel
+
+	for _, line := range lines {
+		if line.Broken {
+			continue
+		}
+
+		opposite := lows
+		parallelType := "support"
+		if line.Type == "support" {
+			opposite = highs
+			parallelType = "resistance"
+		}
+
+		// Offset of the most extreme opposite pivot from the base line
+		var offsets []float64
+		var pivotsInSpan []PivotPoint
+		for _, p := range opposite {
+			if p.Index < line.StartIndex {
+				continue
+			}
+			offsets = append(offsets, p.Price-line.PriceAt(p.Index))
+			pivotsInSpan = append(pivotsInSpan, p)
+		}
+		if len(offsets) < config.MinTouches {
+			continue
+		}
+
+		extreme := offsets[0]
+		for _, o := range offsets {
+			if (parallelType == "support" && o < extreme) || (parallelType == "resistance" && o > extreme) {
+				extreme = o
+			}
+		}
+
+		parallel := Trendline{
+			Type:       parallelType,
+			StartIndex: line.StartIndex,
+			StartPrice: line.StartPrice + extreme,
+			Slope:      line.Slope,
+			Tolerance:  line.Tolerance,
+			AgeBars:    line.AgeBars,
+		}
+		for i, o := range offsets {
+			if math.Abs(o-extreme) <= line.Tolerance {
+				parallel.Anchors = append(parallel.Anchors, pivotsInSpan[i].Index)
+				if parallel.FirstTouch.IsZero() {
+					parallel.FirstTouch = pivotsInSpan[i].Time
+				}
+				parallel.LastTouch = pivotsInSpan[i].Time
+			}
+		}
+		parallel.Touches = len(parallel.Anchors)
+		if parallel.Touches < config.MinTouches {
+			continue
+		}
+		parallel.Score = float64(parallel.Touches) * 10
+
+		if line.Type == "resistance" {
+			channels = append(channels, Channel{Upper: line, Lower: parallel, Base: "upper"})
+		} else {
+			channels = append(channels, Channel{Upper: parallel, Lower: line, Base: "lower"})
+		}
+	}
+
+	return channels
+}
+
+// trendlineZone converts a line to an SRZone at the given candle index, so the
+// nearest-zone logic for stops and targets treats it like a horizontal level
+func trendlineZone(line Trendline, index int, zoneType string, atr float64) SRZone {
+	level := line.PriceAt(index)
+	return SRZone{
+		Level:      level,
+		ZoneTop:    level + line.Tolerance,
+		ZoneBot:    level - line.Tolerance,
+		Strength:   line.Touches,
+		Type:       zoneType,
+		IsBullish:  line.Type == "support",
+		FirstTouch: line.FirstTouch,
+		LastTouch:  line.LastTouch,
+		ZoneRange:  2 * line.Tolerance,
+		PivotCount: line.Touches,
+		AvgATR:     atr,
+	}
+}
+
+// IdentifyTrendlines detects trendlines, channels and recent breaks from the
+// same pivots the S/R zones use, and appends unbroken lines to SRZones
+func (e *TradingEngine) IdentifyTrendlines() {
+	if len(e.Candles) == 0 {
+		return
+	}
+
+	config := DefaultTrendlineConfig()
+	atr := calcATR(e.Candles, e.SRConfig.ATRLength)
+	highs := findPivotHighs(e.Candles, atr, e.SRConfig.LookLeft, e.SRConfig.LookRight,
+		e.SRConfig.ATRMultiplier, e.SRConfig.MaxZonePercent)
+	lows := findPivotLows(e.Candles, atr, e.SRConfig.LookLeft, e.SRConfig.LookRight,
+		e.SRConfig.ATRMultiplier, e.SRConfig.MaxZonePercent)
+
+	e.Trendlines = append(findTrendlines(e.Candles, highs, "resistance", atr, config),
+		findTrendlines(e.Candles, lows, "support", atr, config)...)
+	e.Channels = findChannels(e.Trendlines, highs, lows, config)
+
+	// Break events
+	last := len(e.Candles) - 1
+	e.TrendlineBreaks = nil
+	for _, line := range e.Trendlines {
+		if !line.Broken || last-line.BreakIndex >= config.BreakLookback {
+			continue
+		}
+		direction := "up"
+		if line.Type == "support" {
+			direction = "down"
+		}
+		e.TrendlineBreaks = append(e.TrendlineBreaks, TrendlineBreak{
+			Line:      line,
+			Index:     line.BreakIndex,
+			Time:      e.Candles[line.BreakIndex].OpenTime,
+			Price:     e.Candles[line.BreakIndex].Close,
+			Direction: direction,
+		})
+	}
+
+	// Expose unbroken lines at the current candle as zones
+	currentATR := 0.0
+	if atr[last] > 0 {
+		currentATR = atr[last]
+	}
+	for _, line := range e.Trendlines {
+		if !line.Broken {
+			e.SRZones = append(e.SRZones, trendlineZone(line, last, "trendline", currentATR))
+		}
+	}
+	for _, channel := range e.Channels {
+		// The fitted line is already a trendline zone; add its parallel
+		parallel := channel.Lower
+		if channel.Base == "lower" {
+			parallel = channel.Upper
+		}
+		e.SRZones = append(e.SRZones, trendlineZone(parallel, last, "channel", currentATR))
+	}
+
+	fmt.Printf("✅ Found %d trendline(s), %d channel(s)\n", len(e.Trendlines), len(e.Channels))
+	for _, brk := range e.TrendlineBreaks {
+		fmt.Printf("   🚨 Trendline break %s: closed $%.2f through %s line (%d touches) at %s\n",
+			brk.Direction, brk.Price, brk.Line.Type, brk.Line.Touches, brk.Time.Format("2006-01-02 15:04"))
+	}
+}
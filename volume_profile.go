@@ -0,0 +1,214 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// ==================== VOLUME PROFILE ====================
+
+// VolumeBin holds the traded volume for a single price bucket
+type VolumeBin struct {
+	PriceLow  float64
+	PriceHigh float64
+	Volume    float64
+	BuyVolume float64 // Taker buy volume (aggressive buyers)
+}
+
+// Mid returns the center price of the bin
+func (b VolumeBin) Mid() float64 {
+	return (b.PriceLow + b.PriceHigh) / 2
+}
+
+// VolumeProfile is a volume-at-price histogram over a candle window
+type VolumeProfile struct {
+	Bins        []VolumeBin
+	BinSize     float64
+	TotalVolume float64
+	POCIndex    int       // Bin with the highest volume
+	POC         float64   // Point of control (mid of POC bin)
+	VAH         float64   // Value area high
+	VAL         float64   // Value area low
+	HVNs        []int     // High-volume node bin indices
+	LVNs        []int     // Low-volume node bin indices
+	StartTime   time.Time // Open time of the first candle in the window
+	EndTime     time.Time // Open time of the last candle in the window
+}
+
+// VolumeProfileConfig controls how the profile is built
+type VolumeProfileConfig struct {
+	Window           int     // Number of most recent candles to include (default: 300)
+	Bins             int     // Number of price buckets (default: 50)
+	ValueAreaPercent float64 // % of volume inside the value area (default: 70)
+	HVNThreshold     float64 // Bin volume >= avg * threshold is a HVN (default: 1.5)
+	LVNThreshold     float64 // Bin volume <= avg * threshold is a LVN (default: 0.5)
+}
+
+// DefaultVolumeProfileConfig returns the standard volume profile settings
+func DefaultVolumeProfileConfig() VolumeProfileConfig {
+	return VolumeProfileConfig{
+		Window:           300,
+		Bins:             50,
+		ValueAreaPercent: 70,
+		HVNThreshold:     1.5,
+		LVNThreshold:     0.5,
+	}
+}
+
+// calcVolumeProfile builds a volume-at-price histogram for the most recent
+// config.Window candles. Each candle's volume is spread evenly across the
+// price range it traded (Low to High).
+func calcVolumeProfile(candles []Candle, config VolumeProfileConfig) *VolumeProfile {
+	if len(candles) == 0 || config.Bins <= 0 {
+		return nil
+	}
+
+	start := 0
+	if config.Window > 0 && len(candles) > config.Window {
+		start = len(candles) - config.Window
+	}
+	window := candles[start:]
+
+	// Step 1: Find price range of the window
+	low := window[0].Low
+	high := window[0].High
+	for _, c := range window {
+		low = math.Min(low, c.Low)
+		high = math.Max(high, c.High)
+	}
+	if high <= low {
+		return nil
+	}
+
+	binSize := (high - low) / float64(config.Bins)
+	profile := &VolumeProfile{
+		Bins:      make([]VolumeBin, config.Bins),
+		BinSize:   binSize,
+		StartTime: window[0].OpenTime,
+		EndTime:   window[len(window)-1].OpenTime,
+	}
+	for i := range profile.Bins {
+		profile.Bins[i].PriceLow = low + float64(i)*binSize
+		profile.Bins[i].PriceHigh = low + float64(i+1)*binSize
+	}
+
+	binIndex := func(price float64) int {
+		idx := int((price - low) / binSize)
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= config.Bins {
+			idx = config.Bins - 1
+		}
+		return idx
+	}
+
+	// Step 2: Distribute each candle's volume across the bins it touched
+	for _, c := range window {
+		if c.Volume <= 0 {
+			continue
+		}
+		first := binIndex(c.Low)
+		last := binIndex(c.High)
+		span := float64(last - first + 1)
+		for b := first; b <= last; b++ {
+			profile.Bins[b].Volume += c.Volume / span
+			profile.Bins[b].BuyVolume += c.TakerBuyBaseAssetVolume / span
+		}
+		profile.TotalVolume += c.Volume
+	}
+	if profile.TotalVolume == 0 {
+		return nil
+	}
+
+	// Step 3: Point of control
+	for i, bin := range profile.Bins {
+		if bin.Volume > profile.Bins[profile.POCIndex].Volume {
+			profile.POCIndex = i
+		}
+	}
+	profile.POC = profile.Bins[profile.POCIndex].Mid()
+
+	// Step 4: Value area - expand from POC towards the heavier neighbour
+	// until the configured share of total volume is covered
+	target := profile.TotalVolume * (config.ValueAreaPercent / 100)
+	lowIdx, highIdx := profile.POCIndex, profile.POCIndex
+	covered := profile.Bins[profile.POCIndex].Volume
+	for covered < target && (lowIdx > 0 || highIdx < config.Bins-1) {
+		below, above := -1.0, -1.0
+		if lowIdx > 0 {
+			below = profile.Bins[lowIdx-1].Volume
+		}
+		if highIdx < config.Bins-1 {
+			above = profile.Bins[highIdx+1].Volume
+		}
+		if above >= below {
+			highIdx++
+			covered += above
+		} else {
+			lowIdx--
+			covered += below
+		}
+	}
+	profile.VAH = profile.Bins[highIdx].PriceHigh
+	profile.VAL = profile.Bins[lowIdx].PriceLow
+
+	// Step 5: High/low volume nodes (local peaks and troughs vs. average)
+	avgVolume := profile.TotalVolume / float64(config.Bins)
+	for i := 1; i < config.Bins-1; i++ {
+		if i == profile.POCIndex {
+			continue
+		}
+		v := profile.Bins[i].Volume
+		prev := profile.Bins[i-1].Volume
+		next := profile.Bins[i+1].Volume
+		if v >= avgVolume*config.HVNThreshold && v >= prev && v >= next {
+			profile.HVNs = append(profile.HVNs, i)
+		} else if v <= avgVolume*config.LVNThreshold && v <= prev && v <= next {
+			profile.LVNs = append(profile.LVNs, i)
+		}
+	}
+
+	return profile
+}
+
+// volumeProfileZones converts the profile's key levels into SRZone entries
+// so they can be merged alongside the pivot-based zones
+func volumeProfileZones(profile *VolumeProfile, avgATR float64) []SRZone {
+	if profile == nil {
+		return nil
+	}
+
+	firstTouch := profile.StartTime
+	lastTouch := profile.EndTime
+	halfBin := profile.BinSize / 2
+
+	newZone := func(level float64, zoneType string, strength int) SRZone {
+		return SRZone{
+			Level:      level,
+			ZoneTop:    level + halfBin,
+			ZoneBot:    level - halfBin,
+			Strength:   strength,
+			Type:       zoneType,
+			FirstTouch: firstTouch,
+			LastTouch:  lastTouch,
+			ZoneRange:  profile.BinSize,
+			PivotCount: 0,
+			AvgATR:     avgATR,
+		}
+	}
+
+	zones := []SRZone{
+		newZone(profile.POC, "vpoc", 3),
+		newZone(profile.VAH, "vah", 2),
+		newZone(profile.VAL, "val", 2),
+	}
+	for _, idx := range profile.HVNs {
+		zones = append(zones, newZone(profile.Bins[idx].Mid(), "hvn", 2))
+	}
+	for _, idx := range profile.LVNs {
+		zones = append(zones, newZone(profile.Bins[idx].Mid(), "lvn", 1))
+	}
+
+	return zones
+}